@@ -0,0 +1,79 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// accessPattern matches NCSA common and combined log format lines, as
+// written by Apache and Nginx:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326 "http://ref/" "Mozilla/4.08"
+var accessPattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\S+)(?: "([^"]*)" "([^"]*)")?`)
+
+// accessTimeLayout is the bracketed access log timestamp.
+const accessTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// accessParser parses web server access log lines. The message is the
+// request line and status; 5xx responses are errors and 4xx warnings.
+type accessParser struct{}
+
+func (accessParser) Parse(line string) (Log, int, bool) {
+	match := accessPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return Log{}, 0, false
+	}
+	client, user, stamp, request, status, size := match[1], match[2], match[3], match[4], match[5], match[6]
+
+	log := Log{timestamp: stamp, message: request + " " + status, level: status, severity: Information}
+	if t, err := time.Parse(accessTimeLayout, stamp); err == nil {
+		// Keep the server's wall clock time, as other formats do
+		log.timestamp = t.Format(canonicalTimeFormat)
+	}
+	switch status[0] {
+	case '5':
+		log.severity = Errors
+	case '4':
+		log.severity = Warnings
+	}
+
+	log.fields = map[string]string{"client": client, "status": status, "bytes": size}
+	if user != "-" {
+		log.fields["user"] = user
+	}
+	if match[7] != "" {
+		log.fields["referer"] = match[7]
+	}
+	if match[8] != "" {
+		log.fields["agent"] = match[8]
+	}
+	return log, log.severity, true
+}
+
+// isAccessLog reports whether the first non-blank line of text is an access
+// log line.
+func isAccessLog(text string) bool {
+	for text != "" {
+		var line string
+		line, text, _ = strings.Cut(text, "\n")
+		if line = strings.TrimSpace(line); line != "" {
+			return accessPattern.MatchString(line)
+		}
+	}
+	return false
+}
+
+// parseAccessLog parses every access log line of text, tagging entries
+// with source.
+func parseAccessLog(text, source string) []Log {
+	parse := lineParser(accessParser{})
+	var logs []Log
+	for _, line := range strings.Split(text, "\n") {
+		if log, ok := parse(line); ok {
+			log.source = source
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// category is a tab: a name and the levels it shows. Levels are matched
+// against both the severity bucket ("error", "warning", "info") and the raw
+// level token from the log line ("critical", "debug", ...).
+type category struct {
+	name   string
+	levels []string // lowercase; nil shows everything
+}
+
+// defaultCategories are the tabs shown unless --tabs overrides them.
+var defaultCategories = []category{
+	{name: "Errors", levels: []string{"error"}},
+	{name: "Warnings", levels: []string{"warning"}},
+	{name: "Information", levels: []string{"info"}},
+	{name: "All"},
+}
+
+func (c category) matches(log Log) bool {
+	if c.levels == nil {
+		return true
+	}
+	for _, level := range c.levels {
+		// A promoted warning's raw level no longer describes it
+		if level == severityNames[log.severity] || level == log.level && !log.promoted {
+			return true
+		}
+	}
+	return false
+}
+
+// mixed reports whether the tab can show more than one level, in which case
+// rows are marked with their severity glyph.
+func (c category) mixed() bool {
+	return c.levels == nil || len(c.levels) > 1
+}
+
+// severity returns the severity bucket a single-level tab shows.
+func (c category) severity() (int, bool) {
+	if len(c.levels) != 1 {
+		return 0, false
+	}
+	for sev, name := range severityNames {
+		if c.levels[0] == name {
+			return sev, true
+		}
+	}
+	return 0, false
+}
+
+// parseCategories parses a spec like "Critical=critical,Errors=error,All=*",
+// where each tab lists the levels it shows joined by "+" and "*" shows all.
+func parseCategories(spec string) ([]category, error) {
+	var categories []category
+	for _, def := range strings.Split(spec, ",") {
+		name, levels, ok := strings.Cut(def, "=")
+		name, levels = strings.TrimSpace(name), strings.TrimSpace(levels)
+		if !ok || name == "" || levels == "" {
+			return nil, fmt.Errorf("tab %q must look like Name=level[+level...]", def)
+		}
+		c := category{name: name}
+		if levels != "*" {
+			for _, level := range strings.Split(levels, "+") {
+				c.levels = append(c.levels, strings.ToLower(strings.TrimSpace(level)))
+			}
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+// findTab returns the index of the first tab satisfying pred, or -1.
+func (m *model) findTab(pred func(category) bool) int {
+	for i, c := range m.categories {
+		if pred(c) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+package main
+
+// diffRanges returns the entries of b whose normalized message never appears
+// in a, such as errors that started after a deploy.
+func diffRanges(a, b []Log) []Log {
+	seen := make(map[string]bool, len(a))
+	for _, log := range a {
+		seen[normalizeMessage(log.message)] = true
+	}
+	var added []Log
+	for _, log := range b {
+		if !seen[normalizeMessage(log.message)] {
+			added = append(added, log)
+		}
+	}
+	return added
+}
+
+// rangeLabel describes a start and end date bound for the status bar.
+func rangeLabel(start, end string) string {
+	switch {
+	case start != "" && end != "":
+		return start + " – " + end
+	case start != "":
+		return "from " + start
+	case end != "":
+		return "until " + end
+	}
+	return "all entries"
+}
+
+// setBaseline records the current date range as range A for comparisons.
+func (m *model) setBaseline() {
+	if m.datesDisabled() {
+		return
+	}
+	m.baseline = [2]string{m.startBound(), m.endBound()}
+	m.hasBaseline = true
+	m.status = "Baseline: " + rangeLabel(m.baseline[0], m.baseline[1]) + " (change the dates, then ⇧B to compare)"
+}
+
+// toggleCompare shows the error and warning patterns that appear in the
+// current date range but not in the baseline, grouped like the error
+// summary.
+func (m *model) toggleCompare() {
+	if m.showSummary && m.comparing {
+		m.showSummary, m.comparing = false, false
+		m.initLogTable()
+		return
+	}
+	if !m.hasBaseline {
+		m.status = "Set a baseline range with B first"
+		return
+	}
+	logs := append(append([]Log(nil), m.errors...), m.warnings...)
+	a := filterLogs(logs, "", false, m.baseline[0], m.baseline[1], "")
+	b := filterLogs(logs, "", false, m.startBound(), m.endBound(), "")
+	m.summaries = summarizeErrors(diffRanges(a, b))
+	m.showSummary, m.comparing = true, true
+	m.initLogTable()
+}
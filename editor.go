@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sourceRefPattern matches file:line references such as "app.go:42" or
+// "src/db/pool.py:118".
+var sourceRefPattern = regexp.MustCompile(`([\w.\-/\\]+\.\w+):(\d+)`)
+
+// editorDoneMsg reports the editor exiting.
+type editorDoneMsg struct {
+	err error
+}
+
+// sourceRef is a file:line reference found in a message.
+type sourceRef struct {
+	path string
+	line string
+}
+
+// findSourceRef returns the first reference in message to a file that
+// exists, looking relative to the working directory and then to each of
+// dirs. The first reference is returned, with ok false, when none exist.
+func findSourceRef(message string, dirs []string) (ref sourceRef, ok bool) {
+	matches := sourceRefPattern.FindAllStringSubmatch(message, -1)
+	for _, match := range matches {
+		candidates := []string{match[1]}
+		if !filepath.IsAbs(match[1]) {
+			for _, dir := range dirs {
+				candidates = append(candidates, filepath.Join(dir, match[1]))
+			}
+		}
+		for _, path := range candidates {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return sourceRef{path: path, line: match[2]}, true
+			}
+		}
+	}
+	if len(matches) > 0 {
+		return sourceRef{path: matches[0][1], line: matches[0][2]}, false
+	}
+	return sourceRef{}, false
+}
+
+// openSource opens the first file:line reference in the selected entry in
+// $EDITOR (vi when unset), suspending the UI until the editor exits.
+func (m *model) openSource() tea.Cmd {
+	i := m.logTable.Cursor()
+	if i < 0 || i >= len(m.filteredLogs) {
+		return nil
+	}
+	var dirs []string
+	if m.filePath != "" {
+		dirs = append(dirs, filepath.Dir(m.filePath))
+	}
+	if m.watcher != nil {
+		dirs = append(dirs, m.watcher.dir)
+	}
+	ref, ok := findSourceRef(m.filteredLogs[i].text(), dirs)
+	switch {
+	case ref.path == "":
+		m.status = "No file:line reference in this entry"
+		return nil
+	case !ok:
+		m.status = fmt.Sprintf("%s:%s not found", ref.path, ref.line)
+		return nil
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	// +N jumps to the line in vi, nano, emacs and most others
+	args := append(editor[1:], "+"+ref.line, ref.path)
+	cmd := exec.Command(editor[0], args...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorDoneMsg{err: err}
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+var severityNames = []string{"error", "warning", "info"}
+
+// exportedLog is the JSON shape of an exported log entry.
+type exportedLog struct {
+	Timestamp string `json:"timestamp"`
+	Severity  string `json:"severity"`
+	Source    string `json:"source,omitempty"`
+	Message   string `json:"message"`
+}
+
+// exportFilters records the filters in effect when logs were exported.
+type exportFilters struct {
+	Tab     string `json:"tab"`
+	Query   string `json:"query,omitempty"`
+	Start   string `json:"start,omitempty"`
+	End     string `json:"end,omitempty"`
+	Exclude string `json:"exclude,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+// String renders the filters as a single line for CSV comment headers.
+func (f exportFilters) String() string {
+	return fmt.Sprintf("tab=%s query=%q start=%q end=%q exclude=%q pattern=%q source=%q",
+		f.Tab, f.Query, f.Start, f.End, f.Exclude, f.Pattern, f.Source)
+}
+
+// exportDocument is the self-describing JSON export.
+type exportDocument struct {
+	Filters exportFilters `json:"filters"`
+	Count   int           `json:"count"`
+	Logs    []exportedLog `json:"logs"`
+}
+
+// exportLogs writes logs to path as JSON if it ends in .json, an HTML report
+// if it ends in .html, and CSV otherwise.
+// Unless filters is nil, the export records the filters and row count.
+func exportLogs(path string, logs []Log, filters *exportFilters) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	format := "csv"
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json", ".html":
+		format = ext[1:]
+	}
+	if err := writeLogs(f, format, logs, filters); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// outputFormats are the formats writeLogs accepts.
+var outputFormats = []string{"text", "csv", "json", "html"}
+
+// writeLogs writes logs to w as "json", "csv", an "html" report or
+// tab-separated "text" lines. Unless filters is nil, all but text output
+// records the filters and count.
+func writeLogs(w io.Writer, format string, logs []Log, filters *exportFilters) error {
+	switch format {
+	case "json":
+		entries := make([]exportedLog, len(logs))
+		for i, log := range logs {
+			entries[i] = exportedLog{
+				Timestamp: log.timestamp,
+				Severity:  severityNames[log.severity],
+				Source:    log.source,
+				Message:   log.text(),
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		var doc any = entries
+		if filters != nil {
+			doc = exportDocument{Filters: *filters, Count: len(entries), Logs: entries}
+		}
+		return enc.Encode(doc)
+
+	case "csv":
+		if filters != nil {
+			fmt.Fprintf(w, "# filters: %s\n# count: %d\n", filters, len(logs))
+		}
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"timestamp", "severity", "source", "message"})
+		for _, log := range logs {
+			cw.Write([]string{log.timestamp, severityNames[log.severity], log.source, log.text()})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case "html":
+		return writeHTML(w, logs, filters)
+
+	case "text":
+		bw := bufio.NewWriter(w)
+		for _, log := range logs {
+			bw.WriteString(log.timestamp + "\t" + severityNames[log.severity] + "\t" + log.text() + "\n")
+		}
+		return bw.Flush()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// copyLogs puts logs on the system clipboard, one tab-separated line each.
+func copyLogs(logs []Log) error {
+	var b strings.Builder
+	writeLogs(&b, "text", logs, nil)
+	return clipboard.WriteAll(b.String())
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"html/template"
+	"io"
+)
+
+// htmlReport renders exported logs as a standalone page. The template
+// escapes every value, so messages can't inject markup.
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Log report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #ddd; vertical-align: top; }
+td.message { font-family: monospace; white-space: pre-wrap; }
+tr.error td.severity { color: {{.Colors.error}}; font-weight: bold; }
+tr.warning td.severity { color: {{.Colors.warning}}; }
+tr.info td.severity { color: {{.Colors.info}}; }
+</style>
+</head>
+<body>
+<h1>Log report</h1>
+{{with .Filters}}<p>Tab: {{.Tab}}{{if .Query}} · Search: {{.Query}}{{end}}{{if .Start}} · From: {{.Start}}{{end}}{{if .End}} · To: {{.End}}{{end}}{{if .Exclude}} · Excluding: {{.Exclude}}{{end}}{{if .Pattern}} · Pattern: {{.Pattern}}{{end}}{{if .Source}} · Source: {{.Source}}{{end}}</p>
+{{end}}<p>{{.Count}} entries</p>
+<table>
+<tr><th>Timestamp</th><th>Severity</th><th>Source</th><th>Message</th></tr>
+{{range .Logs}}<tr class="{{.Severity}}"><td>{{.Timestamp}}</td><td class="severity">{{.Severity}}</td><td>{{.Source}}</td><td class="message">{{.Message}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeHTML writes logs to w as an HTML report, noting filters unless nil.
+func writeHTML(w io.Writer, logs []Log, filters *exportFilters) error {
+	entries := make([]exportedLog, len(logs))
+	for i, log := range logs {
+		entries[i] = exportedLog{
+			Timestamp: log.timestamp,
+			Severity:  severityNames[log.severity],
+			Source:    log.source,
+			Message:   log.text(),
+		}
+	}
+	colors := make(map[string]template.CSS, len(severityNames))
+	for sev, name := range severityNames {
+		colors[name] = template.CSS(severityColors[sev])
+	}
+	return htmlReport.Execute(w, struct {
+		Filters *exportFilters
+		Count   int
+		Logs    []exportedLog
+		Colors  map[string]template.CSS
+	}{filters, len(entries), entries, colors})
+}
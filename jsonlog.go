@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys checked, in order, for the standard parts of a JSON log entry.
+var (
+	jsonTimeKeys    = []string{"time", "timestamp", "ts", "@timestamp"}
+	jsonIngestKeys  = []string{"ingest_time", "ingested_at", "received_at", "@ingested"}
+	jsonLevelKeys   = []string{"level", "severity", "lvl"}
+	jsonMessageKeys = []string{"msg", "message"}
+)
+
+// parseJSONLine parses a single-line JSON object such as those written by
+// structured loggers. Every field is kept for the detail view.
+func parseJSONLine(line string) (Log, bool) {
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return Log{}, false
+	}
+
+	fields := make(map[string]string, len(raw))
+	for key, value := range raw {
+		fields[key] = jsonString(value)
+	}
+
+	log := Log{severity: Information, fields: fields}
+	log.timestamp = jsonTime(raw, fields, jsonTimeKeys)
+	log.ingested = jsonTime(raw, fields, jsonIngestKeys)
+	if key := firstKey(raw, jsonLevelKeys); key != "" {
+		level := fields[key]
+		if n, ok := raw[key].(float64); ok {
+			level = numericLevel(n)
+		}
+		log.level = levelToken(level)
+		if sev, ok := parseLevel(level); ok {
+			log.severity = sev
+		} else if log.level != "" {
+			log.severity, log.unknown = unknownSeverity, true
+		}
+	}
+	if key := firstKey(raw, jsonMessageKeys); key != "" {
+		log.message = fields[key]
+	}
+	return log, true
+}
+
+// jsonTime returns the first of keys present in raw as a timestamp, or "".
+func jsonTime(raw map[string]any, fields map[string]string, keys []string) string {
+	key := firstKey(raw, keys)
+	if key == "" {
+		return ""
+	}
+	if epoch, ok := raw[key].(float64); ok {
+		// Unix epoch seconds, or milliseconds once too large to be seconds
+		unit := float64(time.Second)
+		if epoch > 1e11 {
+			unit = float64(time.Millisecond)
+		}
+		return time.Unix(0, int64(epoch*unit)).Format(canonicalTimeFormat)
+	}
+	return fields[key]
+}
+
+// numericLevel names a pino or bunyan numeric level, 10 (trace) to 60
+// (fatal).
+func numericLevel(n float64) string {
+	switch {
+	case n >= 60:
+		return "fatal"
+	case n >= 50:
+		return "error"
+	case n >= 40:
+		return "warn"
+	case n >= 30:
+		return "info"
+	case n >= 20:
+		return "debug"
+	}
+	return "trace"
+}
+
+// firstKey returns the first of keys present in raw, or "".
+func firstKey(raw map[string]any, keys []string) string {
+	for _, key := range keys {
+		if _, ok := raw[key]; ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// jsonString renders a decoded JSON value for display, keeping nested
+// objects and arrays as compact JSON.
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// isJSONLine reports whether line looks like a JSON object.
+func isJSONLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "{")
+}
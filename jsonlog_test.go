@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJSONEpochTimestamps(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+	}{
+		{"seconds", `{"time":1727784000,"msg":"up"}`, time.Unix(1727784000, 0)},
+		{"fractional seconds", `{"time":1727784000.25,"msg":"up"}`, time.Unix(1727784000, 250*int64(time.Millisecond))},
+		{"milliseconds", `{"time":1727784000250,"msg":"up"}`, time.UnixMilli(1727784000250)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, ok := parseJSONLine(tt.line)
+			if !ok {
+				t.Fatal("line not parsed")
+			}
+			if want := tt.want.Format(canonicalTimeFormat); log.timestamp != want {
+				t.Errorf("timestamp %q, want %q", log.timestamp, want)
+			}
+		})
+	}
+}
+
+func TestJSONNumericLevels(t *testing.T) {
+	tests := []struct {
+		level    string
+		severity int
+		name     string
+	}{
+		{"10", Information, "trace"},
+		{"20", Information, "debug"},
+		{"30", Information, "info"},
+		{"40", Warnings, "warn"},
+		{"50", Errors, "error"},
+		{"60", Errors, "fatal"},
+	}
+	for _, tt := range tests {
+		log, ok := parseJSONLine(`{"level":` + tt.level + `,"msg":"m"}`)
+		if !ok {
+			t.Fatalf("level %s: line not parsed", tt.level)
+		}
+		if log.severity != tt.severity || log.level != tt.name || log.unknown {
+			t.Errorf("level %s: got severity %d %q (unknown %v), want %d %q",
+				tt.level, log.severity, log.level, log.unknown, tt.severity, tt.name)
+		}
+	}
+}
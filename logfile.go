@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+const utf8BOM = "\ufeff"
+
+var timePattern = regexp.MustCompile(`^\d{2}:\d{2}(:\d{2})?`)
+
+// parseLogLine is the line parser used for every plain-text source.
+var parseLogLine = parseLine
+
+// parseLine splits a "DATE [TIME] [LEVEL] message" line into a Log. Lines
+// without a recognizable level are treated as information. JSON objects
+// are parsed as structured entries.
+func parseLine(line string) (Log, bool) {
+	if isJSONLine(line) {
+		if log, ok := parseJSONLine(line); ok {
+			return log, true
+		}
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Log{}, false
+	}
+
+	log := Log{timestamp: fields[0], severity: Information}
+	rest := fields[1:]
+	if len(rest) > 0 && timePattern.MatchString(rest[0]) {
+		log.timestamp += " " + rest[0]
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		if sev, ok := parseLevel(rest[0]); ok {
+			log.severity = sev
+			log.level = levelToken(rest[0])
+			rest = rest[1:]
+		} else if bracketedLevel.MatchString(rest[0]) {
+			log.severity, log.unknown = unknownSeverity, true
+			log.level = levelToken(rest[0])
+			rest = rest[1:]
+		}
+	}
+	log.message = strings.Join(rest, " ")
+	return log, true
+}
+
+// grepLines wraps parse so lines are dropped before parsing unless they
+// match include (when set) and don't match exclude (when set).
+func grepLines(parse func(string) (Log, bool), include, exclude *regexp.Regexp) func(string) (Log, bool) {
+	return func(line string) (Log, bool) {
+		if include != nil && !include.MatchString(line) {
+			return Log{}, false
+		}
+		if exclude != nil && exclude.MatchString(line) {
+			return Log{}, false
+		}
+		return parse(line)
+	}
+}
+
+// parseSimpleLine splits line at its first whitespace run into a timestamp
+// and message. When the first token isn't a date the whole line becomes the
+// message. A leading level word in the message still sets the severity.
+func parseSimpleLine(line string) (Log, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Log{}, false
+	}
+	if isJSONLine(line) {
+		if log, ok := parseJSONLine(line); ok {
+			return log, true
+		}
+	}
+
+	log := Log{message: line, severity: Information}
+	if i := strings.IndexFunc(line, unicode.IsSpace); i > 0 {
+		if first := line[:i]; !parseTimestamp(first).IsZero() {
+			log.timestamp = first
+			log.message = strings.TrimLeftFunc(line[i:], unicode.IsSpace)
+		}
+	}
+	if word, _, _ := strings.Cut(log.message, " "); word != "" {
+		if sev, ok := parseLevel(word); ok {
+			log.severity = sev
+			log.level = levelToken(word)
+		}
+	}
+	return log, true
+}
+
+// unknownSeverity is where entries whose level isn't recognized go, set
+// with --unknown-level.
+var unknownSeverity = Information
+
+// bracketedLevel matches a token that can only be a level, like "[NOTE]",
+// even when it isn't one parseLevel knows.
+var bracketedLevel = regexp.MustCompile(`^\[[A-Za-z]+\]$`)
+
+// parseSeverityName maps a severity name such as "warnings" or "error" to
+// its severity.
+func parseSeverityName(name string) (int, error) {
+	for sev, label := range severityLabels {
+		if strings.EqualFold(name, label) || strings.EqualFold(name, severityNames[sev]) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q (known: errors, warnings, information)", name)
+}
+
+// levelToken strips the punctuation around a level word and lowercases it.
+func levelToken(token string) string {
+	return strings.ToLower(strings.Trim(token, "[]():"))
+}
+
+// parseLevel maps a level token such as "[WARN]" or "error:" to a severity.
+func parseLevel(token string) (int, bool) {
+	switch strings.ToUpper(levelToken(token)) {
+	case "ERROR", "ERR", "FATAL", "CRIT", "CRITICAL":
+		return Errors, true
+	case "WARN", "WARNING":
+		return Warnings, true
+	case "INFO", "DEBUG", "TRACE", "NOTICE":
+		return Information, true
+	}
+	return 0, false
+}
+
+// logsMsg carries newly read log entries into the program.
+type logsMsg []Log
+
+// dirWatcher tails every *.log file in a directory, tagging each entry with
+// the name of the file it came from.
+type dirWatcher struct {
+	dir     string
+	watcher *fsnotify.Watcher
+	out     chan<- tea.Msg
+
+	mu      sync.Mutex
+	offsets map[string]int64
+	files   map[string]os.FileInfo // as of the last read, to spot replaced files
+
+	// tailBytes limits the initial load to the end of each file
+	tailBytes int64
+}
+
+// newDirWatcher watches dir, delivering new entries to out as logsMsg.
+func newDirWatcher(dir string, out chan<- tea.Msg) (*dirWatcher, error) {
+	dir = filepath.Clean(dir)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return &dirWatcher{
+		dir:     dir,
+		watcher: watcher,
+		offsets: make(map[string]int64),
+		files:   make(map[string]os.FileInfo),
+		out:     out,
+	}, nil
+}
+
+// load reads the current contents of every log file in the directory.
+func (w *dirWatcher) load() ([]Log, error) {
+	paths, err := filepath.Glob(filepath.Join(w.dir, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	var logs []Log
+	for _, path := range paths {
+		if w.tailBytes > 0 {
+			if offset, err := tailOffset(path, w.tailBytes); err == nil {
+				w.trackFrom(path, offset)
+			}
+		}
+		w.track(path)
+		logs = append(logs, w.readNew(path)...)
+	}
+	// Files that appear later are read in full
+	w.tailBytes = 0
+	return logs, nil
+}
+
+// poll picks up new files and content without relying on change events,
+// which some filesystems (NFS, bind mounts) never deliver.
+func (w *dirWatcher) poll() []Log {
+	logs, _ := w.load()
+	return logs
+}
+
+// track starts following path from its beginning if it isn't already.
+func (w *dirWatcher) track(path string) {
+	w.trackFrom(path, 0)
+}
+
+// trackFrom starts following path from offset if it isn't already.
+func (w *dirWatcher) trackFrom(path string, offset int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.offsets[path]; !ok {
+		w.offsets[path] = offset
+	}
+}
+
+func (w *dirWatcher) forget(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// The file info stays so a file recreated under the name reads as rotated
+	delete(w.offsets, path)
+}
+
+// run forwards entries appended to watched files until the watcher closes.
+func (w *dirWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !isLogFile(event.Name) {
+				continue
+			}
+			switch {
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				w.forget(event.Name)
+			case event.Has(fsnotify.Create), event.Has(fsnotify.Write):
+				w.track(event.Name)
+				if logs := w.readNew(event.Name); len(logs) > 0 {
+					w.out <- logsMsg(logs)
+				}
+			}
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// readNew parses the complete lines appended to path since the last read.
+// A file that shrank or was replaced by another with the same name is
+// assumed to have been rotated; it's re-read from the start after a marker
+// entry.
+func (w *dirWatcher) readNew(path string) []Log {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	offset := w.offsets[path]
+	var logs []Log
+	if info, err := f.Stat(); err == nil {
+		prev, seen := w.files[path]
+		if info.Size() < offset || seen && !os.SameFile(prev, info) {
+			offset = 0
+			logs = append(logs, rotationMarker(filepath.Base(path)))
+		}
+		w.files[path] = info
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil
+	}
+
+	// Leave a trailing partial line for the next read
+	end := bytes.LastIndexByte(data, '\n') + 1
+	w.offsets[path] = offset + int64(end)
+
+	text := string(data[:end])
+	if offset == 0 {
+		text = strings.TrimPrefix(text, utf8BOM)
+	}
+	return append(logs, parseLines(text, filepath.Base(path))...)
+}
+
+// rotationMarker is the entry shown where source was rotated.
+func rotationMarker(source string) Log {
+	return Log{
+		timestamp: time.Now().Format(canonicalTimeFormat),
+		level:     "rotated",
+		message:   "— log rotated; following the new file —",
+		severity:  Information,
+		source:    source,
+	}
+}
+
+// parseLines parses each line of text, tagging entries with source.
+func parseLines(text, source string) []Log {
+	var logs []Log
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if log, ok := parseLogLine(line); ok {
+			log.source = source
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// loadFile reads a single log file, detecting Windows Event Log CSV and XML
+// exports and web server access logs, falling back to plain lines
+// otherwise. A positive tail reads only the complete lines within the
+// file's last tail bytes.
+func loadFile(path string, tail int64) ([]Log, error) {
+	f, err := openTail(path, tail)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), utf8BOM)
+	source := filepath.Base(path)
+
+	switch {
+	case isWindowsXML(text):
+		return parseWindowsXML(text, source)
+	case isWindowsCSV(text):
+		return parseWindowsCSV(text, source)
+	case isAccessLog(text):
+		return parseAccessLog(text, source), nil
+	}
+	return parseLines(text, source), nil
+}
+
+// openTail opens path positioned at the first complete line within its last
+// tail bytes, or at the start when tail isn't positive.
+func openTail(path string, tail int64) (*os.File, error) {
+	var offset int64
+	if tail > 0 {
+		var err error
+		if offset, err = tailOffset(path, tail); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// loadDoneMsg reports that a progressive load has read the whole file.
+type loadDoneMsg struct{}
+
+// loadProgressively streams path to out in batches so the first screen
+// renders before the file is parsed, sending loadDoneMsg at the end.
+// Windows Event Log exports need the whole file and aren't detected.
+func loadProgressively(f *os.File, out chan<- tea.Msg) {
+	streamLines(f, filepath.Base(f.Name()), out)
+	out <- loadDoneMsg{}
+}
+
+// tailOffset returns where the first complete line within the last n bytes
+// of path starts, or 0 if the file is no larger than n.
+func tailOffset(path string, n int64) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() <= n {
+		return 0, nil
+	}
+
+	// A window starting right after a newline has no partial line to skip
+	start := info.Size() - n
+	prev := make([]byte, 1)
+	if _, err := f.ReadAt(prev, start-1); err != nil {
+		return 0, err
+	}
+	if prev[0] == '\n' {
+		return start, nil
+	}
+	line, err := bufio.NewReader(io.NewSectionReader(f, start, n)).ReadBytes('\n')
+	if err == io.EOF {
+		// No line ends within the window
+		return info.Size(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return start + int64(len(line)), nil
+}
+
+func (w *dirWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// isFIFO reports whether path is a named pipe.
+func isFIFO(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeNamedPipe != 0
+}
+
+// followFIFO reads entries from the named pipe at path as they're written.
+// When the writer closes its end the pipe is reopened, which waits for the
+// next writer, so feeds can come and go.
+func followFIFO(path string, out chan<- tea.Msg) {
+	source := filepath.Base(path)
+	for {
+		f, err := os.Open(path)
+		if err != nil {
+			out <- sourceErrMsg{err: err}
+			return
+		}
+		streamLines(f, source, out)
+	}
+}
+
+// sourceErrMsg reports a live source failing after startup.
+type sourceErrMsg struct {
+	err error
+}
+
+// waitForLogs blocks until a live source delivers a message.
+func waitForLogs(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// pollMsg triggers a periodic check for new log content.
+type pollMsg time.Time
+
+// minPollInterval keeps very small intervals from pegging the CPU.
+const minPollInterval = 100 * time.Millisecond
+
+// pollEvery schedules the next poll. Shorter intervals surface new lines
+// sooner at the cost of re-reading the directory more often.
+func pollEvery(interval time.Duration) tea.Cmd {
+	return tea.Tick(max(interval, minPollInterval), func(t time.Time) tea.Msg {
+		return pollMsg(t)
+	})
+}
+
+func isLogFile(path string) bool {
+	return filepath.Ext(path) == ".log"
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// assertCleanMessages fails if any message kept a carriage return or BOM.
+func assertCleanMessages(t *testing.T, logs []Log, want []string) {
+	t.Helper()
+	if len(logs) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(logs), len(want))
+	}
+	for i, log := range logs {
+		if strings.ContainsAny(log.message+log.timestamp, "\r\ufeff") {
+			t.Errorf("entry %d: %q / %q kept a CR or BOM", i, log.timestamp, log.message)
+		}
+		if log.message != want[i] {
+			t.Errorf("entry %d: message %q, want %q", i, log.message, want[i])
+		}
+	}
+}
+
+func TestLoadFileStripsCRLFAndBOM(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "CRLF",
+			content: "2024-10-01 12:00:00 ERROR disk full\r\n2024-10-01 12:00:01 INFO recovered\r\n",
+			want:    []string{"disk full", "recovered"},
+		},
+		{
+			name:    "BOM and CRLF",
+			content: "\ufeff2024-10-01 12:00:00 ERROR disk full\r\n2024-10-01 12:00:01 INFO recovered\r\n",
+			want:    []string{"disk full", "recovered"},
+		},
+		{
+			name:    "BOM without trailing newline",
+			content: "\ufeff2024-10-01 12:00:00 ERROR disk full",
+			want:    []string{"disk full"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			logs, err := loadFile(path, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertCleanMessages(t, logs, tt.want)
+		})
+	}
+}
+
+// newTestWatcher returns a dirWatcher that reads files without watching.
+func newTestWatcher() *dirWatcher {
+	return &dirWatcher{
+		offsets: make(map[string]int64),
+		files:   make(map[string]os.FileInfo),
+	}
+}
+
+func TestReadNewStripsBOM(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		more    string // appended before a second read
+		want    []string
+	}{
+		{
+			name:    "BOM and CRLF",
+			content: "\ufeff2024-10-01 12:00:00 ERROR disk full\r\n2024-10-01 12:00:01 INFO recovered\r\n",
+			want:    []string{"disk full", "recovered"},
+		},
+		{
+			// The partial line is left unread, so the second read is
+			// still at offset 0 and must strip the BOM itself
+			name:    "BOM without trailing newline",
+			content: "\ufeff2024-10-01 12:00:00 ERROR disk full",
+			more:    "\r\n",
+			want:    []string{"disk full"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			w := newTestWatcher()
+			w.track(path)
+			logs := w.readNew(path)
+			if tt.more != "" {
+				if len(logs) != 0 {
+					t.Fatalf("read %d entries from a partial line", len(logs))
+				}
+				if w.offsets[path] != 0 {
+					t.Fatalf("offset %d after a partial line, want 0", w.offsets[path])
+				}
+				appendFile(t, path, tt.more)
+				logs = w.readNew(path)
+			}
+			assertCleanMessages(t, logs, tt.want)
+		})
+	}
+}
+
+// appendFile appends text to the file at path.
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadNewAfterRotation(t *testing.T) {
+	rotations := []struct {
+		name   string
+		rotate func(t *testing.T, path, content string)
+	}{
+		{"truncated", func(t *testing.T, path, content string) {
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"replaced", func(t *testing.T, path, content string) {
+			next := path + ".new"
+			if err := os.WriteFile(next, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(next, path); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range rotations {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			old := "2024-10-01 12:00:00 INFO old one\n2024-10-01 12:00:01 INFO old two\n2024-10-01 12:00:02 INFO old three\n"
+			if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			w := newTestWatcher()
+			w.track(path)
+			if logs := w.readNew(path); len(logs) != 3 {
+				t.Fatalf("first read got %d entries, want 3", len(logs))
+			}
+
+			tt.rotate(t, path, "2024-10-01 13:00:00 INFO new one\n")
+			appendFile(t, path, "2024-10-01 13:00:01 INFO new two\n")
+			logs := w.readNew(path)
+
+			markers := 0
+			var got []string
+			for _, log := range logs {
+				if log.level == "rotated" {
+					markers++
+					continue
+				}
+				got = append(got, log.message)
+			}
+			if markers != 1 {
+				t.Errorf("got %d rotation markers, want 1", markers)
+			}
+			if strings.Join(got, "|") != "new one|new two" {
+				t.Errorf("after rotation read %q, want only the new lines", got)
+			}
+			if logs := w.readNew(path); len(logs) != 0 {
+				t.Errorf("a read with nothing new got %d entries", len(logs))
+			}
+		})
+	}
+}
+
+func TestLoadFileSyslogPriorities(t *testing.T) {
+	defer func(saved func(string) (Log, bool)) { parseLogLine = saved }(parseLogLine)
+	parseLogLine = lineParser(syslogParser{})
+
+	path := filepath.Join(t.TempDir(), "messages")
+	content := "<34>Oct  1 12:00:00 host sshd[42]: auth failed\n<14>Oct  1 12:00:01 host cron: job done\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := loadFile(path, 0)
+	if err != nil {
+		t.Fatalf("syslog with priorities was read as XML: %v", err)
+	}
+	assertCleanMessages(t, logs, []string{"auth failed", "job done"})
+	if logs[0].severity != Errors || logs[1].severity != Information {
+		t.Errorf("severities %d and %d, want %d and %d", logs[0].severity, logs[1].severity, Errors, Information)
+	}
+}
+
+// severityParser returns a fixed severity for every line.
+type severityParser int
+
+func (p severityParser) Parse(line string) (Log, int, bool) {
+	return Log{message: line}, int(p), true
+}
+
+func TestLineParserClampsSeverity(t *testing.T) {
+	for _, severity := range []int{-1, 3, 7} {
+		log, _ := lineParser(severityParser(severity))("plugin line")
+		if log.severity != unknownSeverity || !log.unknown {
+			t.Errorf("severity %d became %d (unknown %v), want %d", severity, log.severity, log.unknown, unknownSeverity)
+		}
+	}
+}
@@ -1,14 +1,27 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 var (
@@ -40,19 +53,223 @@ var (
 	helpSeparatorStyle = lipgloss.NewStyle().
 				Background(lipgloss.Color("#444444")).
 				Foreground(lipgloss.Color("#888888"))
+	errorCountStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FF5F5F"))
+	warningCountStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFD75F"))
+	infoCountStyle = lipgloss.NewStyle().
+			Faint(true)
+	followStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#5FD75F"))
+	pausedStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FFD75F"))
+	scrollThumbStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#7D5674"))
+	scrollTrackStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#444444"))
+	matchStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("#FFD75F")).
+			Foreground(lipgloss.Color("#000000"))
+	selectedMatchStyle = lipgloss.NewStyle().
+				Bold(true).
+				Background(lipgloss.Color("#FF7CCB")).
+				Foreground(lipgloss.Color("#000000"))
+	detailStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#7D5674")).
+			Padding(0, 1)
+	emptyStyle = lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color("#888888"))
+	dayStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#7D5674"))
+	stripeStyle = lipgloss.NewStyle().
+			Background(lipgloss.AdaptiveColor{Light: "#EEEEEE", Dark: "#262626"})
+	validStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#5FD75F"))
+	invalidStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F5F"))
 )
 
+// Severities, each shown by its own tab by default.
 const (
 	Errors = iota
 	Warnings
 	Information
 )
 
+var severityLabels = []string{"Errors", "Warnings", "Information"}
+
+// Columns that can be shown in the log table, with their titles and fixed
+// widths. The message column has no fixed width.
+var (
+	columnTitles = map[string]string{
+		"timestamp": "Timestamp",
+		"level":     "Level",
+		"source":    "Source",
+		"delta":     "Δ",
+		"ingested":  "Ingested",
+		"message":   "Message",
+	}
+	columnWidths = map[string]int{
+		"timestamp": 20,
+		"level":     8,
+		"source":    16,
+		"delta":     8,
+		"ingested":  20,
+	}
+)
+
+// parseColumns validates a comma-separated list of column names.
+func parseColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := columnTitles[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q (known: timestamp, level, source, delta, ingested, message)", name)
+		}
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns given")
+	}
+	return columns, nil
+}
+
+// Colors and glyphs used to mark each severity, indexed by severity. The
+// dim colors outline inactive tabs.
+var (
+	severityColors    = []lipgloss.Color{"#FF5F5F", "#FFD75F", "#5FAFFF"}
+	severityDimColors = []lipgloss.Color{"#8B3A3A", "#8B7A3A", "#3A5F8B"}
+	severityGlyphs    = []string{"✖", "▲", "●"}
+)
+
 type Log struct {
+	id        int
 	timestamp string
+	at        time.Time // parsed timestamp, zero if unparsable
 	message   string
+	level     string // raw level token, lowercase
+	full      string // untruncated message, set only with --keep-full
+	raw       string // message before --trim, shown in the detail view
+	severity  int
+	source    string
+	promoted  bool              // a repeated warning raised to an error
+	unknown   bool              // had a level token that wasn't recognized
+	fields    map[string]string // every field of a structured (JSON) entry
+
+	ingested   string    // when the entry was ingested, if the log says
+	ingestedAt time.Time // parsed ingest time, zero if unparsable
+}
+
+// swapTimes exchanges the event and ingest times, so that whichever is in
+// timestamp drives sorting and date filtering.
+func (l Log) swapTimes() Log {
+	l.timestamp, l.ingested = l.ingested, l.timestamp
+	l.at, l.ingestedAt = l.ingestedAt, l.at
+	return l
+}
+
+// sortLogs orders logs chronologically. With bySeverity, entries sharing a
+// timestamp put errors first, then warnings, then info.
+func sortLogs(logs []Log, bySeverity bool) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		a, b := logs[i], logs[j]
+		if a.before(b) {
+			return true
+		}
+		if !bySeverity || a.timestamp == "" || b.timestamp == "" || b.before(a) {
+			return false
+		}
+		return a.severity < b.severity
+	})
+}
+
+// noTimestamp stands in for the timestamp of entries that had none.
+const noTimestamp = "<no time>"
+
+// before orders logs chronologically, using the parsed time when both have
+// one so that sub-second timestamps sort at full precision. Entries without
+// a timestamp go last, in the order they were read.
+func (l Log) before(other Log) bool {
+	if l.timestamp == "" || other.timestamp == "" {
+		if l.timestamp != other.timestamp {
+			return other.timestamp == ""
+		}
+		return l.id < other.id
+	}
+	if !l.at.IsZero() && !other.at.IsZero() {
+		return l.at.Before(other.at)
+	}
+	return l.timestamp < other.timestamp
+}
+
+// text returns the full message when it was kept, the displayed one otherwise.
+func (l Log) text() string {
+	if l.full != "" {
+		return l.full
+	}
+	return l.message
+}
+
+// errorSummary groups errors sharing the same normalized message.
+type errorSummary struct {
+	pattern  string
+	severity int
+	count    int
+	first    string
+	last     string
+}
+
+var (
+	idPattern    = regexp.MustCompile(`\b[0-9a-fA-F-]{8,}\b`)
+	digitPattern = regexp.MustCompile(`\d+`)
+)
+
+// timestampLayouts are tried in order when parsing log timestamps.
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseTimestamp(ts string) time.Time {
+	for _, layout := range timestampLayouts {
+		// Timestamps without a zone are assumed to be local time
+		if t, err := time.ParseInLocation(layout, ts, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
 }
 
+// canonicalDateFormat is the sortable layout logs are timestamped with
+// internally; date bounds are normalized to it before comparing.
+const canonicalDateFormat = "2006-01-02"
+
+// canonicalTimeFormat extends canonicalDateFormat for time-aware bounds.
+const canonicalTimeFormat = "2006-01-02 15:04:05"
+
+// layoutLabel renders a Go time layout the way users expect to read it.
+var layoutLabel = strings.NewReplacer(
+	"2006", "YYYY",
+	"01", "MM",
+	"02", "DD",
+	"Jan", "MMM",
+	"15", "hh",
+	"04", "mm",
+	"05", "ss",
+)
+
 type focusedInput int
 
 const (
@@ -60,6 +277,10 @@ const (
 	searchBoxFocused
 	startDateFocused
 	endDateFocused
+	excludeDateFocused
+	exportFocused
+	overwriteFocused
+	findFocused
 )
 
 type model struct {
@@ -70,295 +291,4189 @@ type model struct {
 	searchBox    textinput.Model
 	startDate    textinput.Model
 	endDate      textinput.Model
+	excludeDate  textinput.Model // hides every entry on one date
 	searchQuery  string
+	dateFormat   string
 	errors       []Log
 	warnings     []Log
 	info         []Log
 	filteredLogs []Log
 	logTable     table.Model
+	watcher      *dirWatcher
+	live         chan tea.Msg // entries from followed sources
+	pollInterval time.Duration
+
+	showLegend     bool
+	showStats      bool // severity breakdown of the shown rows
+	showMatchDays  bool // list the busiest days for the search in the status bar
+	matchDays      []dayCount
+	showSummary    bool
+	showKeys       bool      // the F1 overlay listing every key
+	comparing      bool      // the summary lists patterns new since the baseline
+	baseline       [2]string // start and end of the range compared against
+	hasBaseline    bool
+	summaries      []errorSummary
+	summaryPattern string
+	sourceFilter   string
+
+	nextID      int
+	selected    map[int]bool // keyed by Log.id
+	exportPath  string
+	exportBox   textinput.Model
+	pendingPath string // export path awaiting overwrite confirmation
+	findBox     textinput.Model
+	findQuery   string // moved between with n/N without filtering
+	plainExport bool
+	redact      redactor // nil unless --redact
+	searchRaw   bool     // search unredacted messages
+	normalize   displayRules
+	launchArgs  []string // flags needed to reload the same logs the same way
+	status      string
+	gap         time.Duration
+
+	liveFilter bool
+	filterSeq  int // debounces live filtering
+
+	maxMsgLen      int
+	keepFull       bool
+	trimSpace      bool // trim whitespace around messages when loading
+	collapseSpaces bool // also squeeze runs of spaces and tabs inside them
+
+	filtersVisible bool
+	columns        []string // nil shows the default columns
+	noAltScreen    bool
+	perTabFilters  bool
+	highlight      bool           // highlight search matches in messages
+	wrapAround     bool           // moving past the last row goes to the first, and back
+	wholeWord      bool           // search matches whole words only
+	zebra          bool           // alternate row backgrounds
+	severityTies   bool           // entries sharing a timestamp sort errors first
+	zoomed         bool           // date range narrowed to the selected entry's minute or hour
+	zoomFrom       [2]string      // start and end dates from before zooming
+	queryLang      bool           // search with AND/OR/NOT expressions
+	queryErr       error          // why the search expression didn't parse
+	regexSearch    bool           // search with a regular expression
+	searchRegex    *regexp.Regexp // the last search pattern that compiled
+	regexErr       error          // why the pattern being typed doesn't compile
+	onUpdate       int            // cursorKeep, cursorNewest or cursorFirst
+	arrivals       []arrival      // followed batches within the rate window
+	unknownLevels  int            // loaded entries whose level wasn't recognized
+	hasIngest      bool           // some entry has an ingest time as well as an event time
+	byIngest       bool           // ingest rather than event time drives sorting and dates
+	messageWidth   int
+	categories     []category
+	tabFilters     []tabFilters // per category, in per-tab filter mode
+	tabMatches     []int        // per category, entries passing the current filters
+	tabTotals      []int        // per category, entries before filtering
+	tabCursors     []int        // per category, cursor when the tab was last left
+	tsPrecision    int          // fractional second digits shown
+	relativeTime   bool
+	collapseTimes  bool // blank timestamps repeated from the previous row
+
+	filePath        string           // --file, when it's a local file
+	origin          string           // what was loaded, shown with --show-origin
+	loadedAt        time.Time        // when the entries were last loaded
+	fileKeys        map[string][]int // logKey to IDs, in file order, for entries from filePath
+	tailBytes       int64            // load only the end of large files
+	loading         bool             // filePath is still being streamed in
+	loadOnly        bool             // live exists just for the progressive load
+	autoRefresh     bool
+	refreshInterval time.Duration
+	refreshSeq      int
+	idleTimeout     time.Duration // quit after this long without input or new entries
+	lastActivity    time.Time
+
+	debug      bool
+	loadStart  time.Time
+	loadTime   time.Duration
+	loadRows   int
+	filterTime time.Duration
+
+	applied       filterState
+	filterHistory []filterState
+
+	confirmingQuit bool // quit pressed while following, awaiting y/n
+
+	timestampWidth int  // set by dragging the column edge; 0 fits the content
+	timestampX     int  // screen column where the timestamp column starts, -1 if hidden
+	dragging       bool // resizing the timestamp column with the mouse
+
+	showDelta  bool         // show the time since the previous row
+	showLevel  bool         // show each entry's raw level
+	clockCheck bool         // mark entries stamped earlier than the one before
+	outOfOrder map[int]bool // IDs flagged by the clock check
+
+	showDetail bool // show the selected entry in full instead of the table
+	clock12    bool // show times of day on a 12-hour clock
+	recency    bool // color timestamps by age
+	dateOnly   bool // timestamps cut to their date
+	timeDetail bool // show timestamps in full rather than just their date
+
+	hidden [3]bool // severities hidden from mixed tabs, indexed by severity
+
+	noDates bool // nothing loaded had a timestamp, so dates can't filter
+
+	promoteAfter  int // applied once the initial load finishes
+	promoteWindow time.Duration
+
+	quitKey  string    // "q", "qq" (double press) or "ctrl+c"
+	lastQKey time.Time // first press of a double-press quit
+
+	batchOnly bool      // show only the newest batch from a followed source
+	batchFrom int       // first ID in the newest batch
+	batchAt   time.Time // when the newest batch arrived
+
+	mark      *Log // bookmark set with M
+	afterMark bool // show only entries from the mark on
+}
+
+// tabFilters holds one tab's search and date inputs in per-tab filter mode.
+type tabFilters struct {
+	query   string
+	start   string
+	end     string
+	exclude string
+}
+
+// filterState captures everything that determines the filtered view.
+type filterState struct {
+	tab            int
+	query          string
+	start          string
+	end            string
+	exclude        string
+	summaryPattern string
+	sourceFilter   string
+	hidden         [3]bool
+}
+
+// maxFilterHistory caps how many filter changes can be undone.
+const maxFilterHistory = 20
+
+// searchPlaceholder is shown in an empty search box until a search is
+// cleared, after which the cleared term is shown for recall instead.
+const searchPlaceholder = "Enter keyword"
+
+// filterDebounce is how long live filtering waits after the last keystroke.
+const filterDebounce = 150 * time.Millisecond
+
+// liveFilterMsg applies live filters once typing has paused.
+type liveFilterMsg struct {
+	seq int
 }
 
 func (m *model) Init() tea.Cmd {
 	// Initialize tables
 	m.initLogTable()
 	// m.initHelpTable()
-	return tea.EnterAltScreen
+	var cmds []tea.Cmd
+	if !m.noAltScreen {
+		cmds = append(cmds, tea.EnterAltScreen)
+	}
+	if m.origin != "" {
+		cmds = append(cmds, tea.SetWindowTitle("Log Analyzer — "+m.origin))
+	}
+	if m.watcher != nil {
+		go m.watcher.run()
+		cmds = append(cmds, pollEvery(m.pollInterval))
+	}
+	if m.idleTimeout > 0 {
+		m.lastActivity = time.Now()
+		cmds = append(cmds, m.idleCheck())
+	}
+	if m.live != nil {
+		cmds = append(cmds, waitForLogs(m.live))
+		if !m.loadOnly {
+			cmds = append(cmds, rateTick())
+		}
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *model) initLogTable() {
-	columns := []table.Column{
-		{Title: "Timestamp", Width: 20},
-		{Title: "Message", Width: m.width - 22}, // Remaining width for message
+	if m.showSummary {
+		m.initSummaryTable()
+		return
 	}
 
-	// Convert filtered logs to table rows
-	rows := make([]table.Row, len(m.filteredLogs))
-	for i, log := range m.filteredLogs {
-		rows[i] = table.Row{log.timestamp, log.message}
+	names := m.visibleColumns()
+	var columns []table.Column
+	if len(m.selected) > 0 {
+		columns = append(columns, table.Column{Title: "✓", Width: 1})
+	}
+
+	// Widen the timestamp column to fit fractional seconds
+	widths := map[string]int{}
+	for name, width := range columnWidths {
+		widths[name] = width
+	}
+	m.dateOnly = !m.timeDetail
+	for i := range m.filteredLogs {
+		widths["timestamp"] = max(widths["timestamp"], runewidth.StringWidth(m.timestampCell(i)))
+	}
+	if m.timestampWidth > 0 {
+		widths["timestamp"] = m.timestampWidth
+	}
+
+	// Space left for the named columns and their cell padding
+	available := m.tableWidth()
+	if m.needsScrollbar() {
+		available -= 2
+	}
+	for _, c := range columns {
+		available -= c.Width + 2
+	}
+	available -= 2 * len(names)
+	// A narrow terminal cuts timestamps to their date even when full ones are wanted
+	m.dateOnly = balanceColumns(names, widths, available) || !m.timeDetail
+
+	// Remember where the timestamp column sits for mouse resizing
+	m.timestampX = -1
+	x := 0
+	for _, c := range columns {
+		x += c.Width + 2
+	}
+	for _, name := range names {
+		if name == "timestamp" {
+			m.timestampX = x
+			break
+		}
+		x += widths[name] + 2
+	}
+
+	for _, name := range names {
+		if name == "message" {
+			m.messageWidth = widths[name]
+		}
+		title := columnTitles[name]
+		if name == "ingested" && m.byIngest {
+			// The ingest time has moved to the timestamp column
+			title = "Event"
+		}
+		columns = append(columns, table.Column{Title: title, Width: widths[name]})
 	}
 
 	m.logTable = table.New(
 		table.WithColumns(columns),
-		table.WithRows(rows),
-		table.WithHeight(10),
+		table.WithRows(m.tableRows(0)),
+		table.WithHeight(m.tableHeight()),
 		table.WithFocused(m.focused == logFocus),
 	)
 }
 
-func (m model) renderHelpFooter() string {
-	var help strings.Builder
-
-	// Define help items
-	helpItems := []struct {
-		key         string
-		description string
-	}{
-		{"^Q", "Exit"},
-		{"Tab", "Switch Tab"},
-		{"/", "Search"},
-		{"F", "Start Date"},
-		{"E", "End Date"},
-		{"^C", "Cancel"},
-		{"Enter", "Apply"},
+// Narrowest widths columns are shrunk to on small terminals, and the width
+// the message column is kept to while the others can still give way.
+var (
+	columnMinWidths = map[string]int{
+		"timestamp": len(canonicalDateFormat),
+		"level":     5,
+		"source":    8,
+		"delta":     6,
+		"ingested":  len(canonicalDateFormat),
 	}
+	minMessageWidth = 20
+)
 
-	separator := helpSeparatorStyle.Render(" | ")
-
-	// Create the help line
-	columnWidth := 15
-	width := 0
-	help.WriteString(helpStyle.Render("  "))
-	for i, item := range helpItems {
-		if i > 0 {
-			help.WriteString(separator)
-		}
-		if width > m.width {
-			help.WriteString("\n")
+// balanceColumns sets widths["message"] to the width the fixed columns leave
+// of available. When that's under minMessageWidth it first cuts timestamps to
+// their date, then shrinks the fixed columns toward their minimums in
+// proportion to how far above them they are. It reports whether timestamps
+// were cut.
+func balanceColumns(names []string, widths map[string]int, available int) (dateOnly bool) {
+	if !slices.Contains(names, "message") {
+		return false
+	}
+	fixed := func() int {
+		total := 0
+		for _, name := range names {
+			if name != "message" {
+				total += widths[name]
+			}
 		}
-		help.WriteString(helpKeyStyle.Render(item.key))
-		help.WriteString(helpStyle.Render(" " + item.description))
-		width += columnWidth
+		return total
 	}
 
-	// Create border line
-	width = m.width
-	if width == 0 {
-		width = 80 // fallback width
+	date := columnMinWidths["timestamp"]
+	if fixed() > available-minMessageWidth && slices.Contains(names, "timestamp") && widths["timestamp"] > date {
+		widths["timestamp"] = date
+		dateOnly = true
 	}
 
-	// Pad the help text to full width
-	helpText := help.String()
-	padding := width - lipgloss.Width(helpText)
-	if padding > 0 {
-		helpText += helpStyle.Render(strings.Repeat(" ", padding))
+	if over := fixed() - (available - minMessageWidth); over > 0 {
+		slack := 0
+		for _, name := range names {
+			if name != "message" {
+				slack += max(widths[name]-columnMinWidths[name], 0)
+			}
+		}
+		for _, name := range names {
+			if name == "message" || slack == 0 {
+				continue
+			}
+			give := max(widths[name]-columnMinWidths[name], 0)
+			// Round up so the cuts add up to at least over
+			widths[name] -= min(give, (over*give+slack-1)/slack)
+		}
 	}
 
-	return helpText
+	widths["message"] = max(available-fixed(), 1)
+	return dateOnly
 }
 
-func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
+// tableRows converts the filtered logs to table rows, highlighting search
+// matches with a distinct style on the row under the cursor.
+func (m *model) tableRows(cursor int) []table.Row {
+	names := m.visibleColumns()
+	query := ""
+	if m.highlight && !m.queryLang && !m.regexSearch {
+		query, _, _ = parseAnchors(m.searchBox.Value())
+	}
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "q":
-			if m.focused == logFocus {
-				return m, tea.Quit
-			}
-		case "tab":
-			m.activeTab = (m.activeTab + 1) % 3
-			m.applyFilters() // Update filtered logs for new tab
-			m.initLogTable() // Reinitialize table with new data
-		case "shift+tab":
-			m.activeTab = (m.activeTab + 3 - 1) % 3
-			m.applyFilters() // Update filtered logs for new tab
-			m.initLogTable() // Reinitialize table with new data
-		case "/":
-			if m.focused == logFocus {
-				m.focused = searchBoxFocused
-				m.searchBox.Focus()
-				m.startDate.Blur()
-				m.endDate.Blur()
-			}
-		case "f":
-			if m.focused == logFocus {
-				m.focused = startDateFocused
-				m.startDate.Focus()
-				m.searchBox.Blur()
-				m.endDate.Blur()
+	rows := make([]table.Row, len(m.filteredLogs))
+	for i, log := range m.filteredLogs {
+		var row table.Row
+		if len(m.selected) > 0 {
+			mark := " "
+			if m.selected[log.id] {
+				mark = "✓"
 			}
-		case "e":
-			if m.focused == logFocus {
-				m.focused = endDateFocused
-				m.endDate.Focus()
-				m.searchBox.Blur()
-				m.startDate.Blur()
+			row = append(row, mark)
+		}
+		for _, name := range names {
+			value := m.cell(name, i)
+			if name == "timestamp" {
+				value = m.timestampCell(i)
 			}
-		case "esc":
-			m.clearFocusedFilter()
-			m.focused = logFocus
-			m.searchBox.Blur()
-			m.startDate.Blur()
-			m.endDate.Blur()
-			m.initLogTable() // Reinitialize table after clearing filter
-		case "enter":
-			if m.focused == searchBoxFocused || m.focused == startDateFocused || m.focused == endDateFocused {
-				m.applyFilters()
-				m.initLogTable() // Reinitialize table after applying filters
-				m.focused = logFocus
+			if name == "message" && query != "" {
+				base, match := lipgloss.NewStyle(), matchStyle
+				if i == cursor {
+					base, match = table.DefaultStyles().Selected, selectedMatchStyle
+				}
+				value = highlightMatches(value, query, m.wholeWord, base, match, m.messageWidth)
 			}
+			row = append(row, value)
 		}
+		rows[i] = row
+	}
+	return rows
+}
 
-		// Handle table navigation when focused on logs
-		if m.focused == logFocus {
-			var tableMsg tea.Msg = msg
-			m.logTable, cmd = m.logTable.Update(tableMsg)
-			return m, cmd
-		}
+// recencyColors shade timestamps from bright to dim with age; entries older
+// than the last threshold get the last color.
+var (
+	recencyAges   = []time.Duration{time.Minute, 10 * time.Minute, time.Hour, 24 * time.Hour}
+	recencyColors = []lipgloss.Color{"#FFFFFF", "#D0D0D0", "#A8A8A8", "#808080", "#585858"}
+)
 
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.initLogTable() // Reinitialize table with new dimensions
-		return m, tea.ClearScreen
+// timestampCell renders the i-th filtered log's timestamp, colored by age
+// in recency mode. The table truncates by raw width, so the column is sized
+// from this styled value.
+func (m *model) timestampCell(i int) string {
+	value := m.cell("timestamp", i)
+	at := m.filteredLogs[i].at
+	if !m.recency || value == "" || at.IsZero() {
+		return value
 	}
-
-	switch m.focused {
-	case searchBoxFocused:
-		m.searchBox, cmd = m.searchBox.Update(msg)
-	case startDateFocused:
-		m.startDate, cmd = m.startDate.Update(msg)
-	case endDateFocused:
-		m.endDate, cmd = m.endDate.Update(msg)
+	age := time.Since(at)
+	shade := len(recencyAges)
+	for j, limit := range recencyAges {
+		if age < limit {
+			shade = j
+			break
+		}
 	}
-
-	m.searchQuery = m.searchBox.Value()
-	return m, cmd
+	return lipgloss.NewStyle().Foreground(recencyColors[shade]).Render(value)
 }
 
-func (m model) View() string {
-	content := strings.Builder{}
-
-	// Title
-	title := lipgloss.PlaceHorizontal(m.width, lipgloss.Center, titleStyle.Render("System Log Analyzer"))
-	content.WriteString(title + "\n\n")
+// setCursor moves the table cursor, re-rendering rows when highlighting so
+// the selected row's matches keep their distinct style.
+func (m *model) setCursor(i int) {
+	m.logTable.SetCursor(i)
+	if m.highlight && m.searchBox.Value() != "" && !m.showSummary {
+		m.logTable.SetRows(m.tableRows(m.logTable.Cursor()))
+	}
+}
 
-	// Tab bar
-	tabBar := ""
-	switch m.activeTab {
-	case Errors:
-		tabBar = lipgloss.JoinHorizontal(lipgloss.Top,
-			activeTab.Render("Errors"),
-			tab.Render("Warnings"),
-			tab.Render("Information"))
-	case Warnings:
-		tabBar = lipgloss.JoinHorizontal(lipgloss.Top,
-			tab.Render("Errors"),
-			activeTab.Render("Warnings"),
-			tab.Render("Information"))
-	case Information:
-		tabBar = lipgloss.JoinHorizontal(lipgloss.Top,
-			tab.Render("Errors"),
-			tab.Render("Warnings"),
-			activeTab.Render("Information"))
-	}
-	content.WriteString(tabBar + "\n\n")
+// highlightMatches styles case-insensitive occurrences of query in text, or
+// only whole-word ones with wholeWord. Matching works on runes so multibyte
+// text is never split mid-character. The table truncates cells by their raw
+// display width, escape codes included, so segments are added only while
+// the styled result still fits.
+func highlightMatches(text, query string, wholeWord bool, base, match lipgloss.Style, width int) string {
+	runes, term := []rune(text), foldRunes([]rune(query))
+	folded := foldRunes(runes)
+	index := indexRunes
+	if wholeWord {
+		index = indexWord
+	}
+	if len(term) == 0 || index(folded, term) < 0 {
+		return text
+	}
 
-	// Search and date filters
-	content.WriteString("Search: " + m.searchBox.View() + "\n\n")
-	content.WriteString("Start Date (YYYY-MM-DD): " + m.startDate.View() + "\n")
-	content.WriteString("End Date (YYYY-MM-DD): " + m.endDate.View() + "\n\n")
+	const ellipsis = "…"
+	budget := width - runewidth.StringWidth(ellipsis)
+	var b strings.Builder
+	used := 0
 
-	// Log table
-	content.WriteString("\nLogs:\n")
-	content.WriteString(m.logTable.View())
+	// add appends seg in style if it fits, or as much of it as fits
+	// followed by an ellipsis. It reports whether seg fit entirely.
+	add := func(seg []rune, style lipgloss.Style) bool {
+		rendered := style.Render(string(seg))
+		if w := runewidth.StringWidth(rendered); used+w <= budget {
+			b.WriteString(rendered)
+			used += w
+			return true
+		}
+		room := budget - used - (runewidth.StringWidth(style.Render("x")) - 1)
+		cut, w := 0, 0
+		for cut < len(seg) && w+runewidth.RuneWidth(seg[cut]) <= room {
+			w += runewidth.RuneWidth(seg[cut])
+			cut++
+		}
+		if cut > 0 {
+			b.WriteString(style.Render(string(seg[:cut])))
+		}
+		b.WriteString(ellipsis)
+		return false
+	}
 
-	// Help table
-	content.WriteString("\nHelp:\n")
-	content.WriteString(m.renderHelpFooter())
+	matched := false
+	for i := 0; i < len(runes); {
+		j := index(folded[i:], term)
+		if j < 0 {
+			add(runes[i:], base)
+			break
+		}
+		if j > 0 && !add(runes[i:i+j], base) {
+			break
+		}
+		if !add(runes[i+j:i+j+len(term)], match) {
+			break
+		}
+		matched = true
+		i += j + len(term)
+	}
+	if !matched {
+		// No match fits; let the table truncate the plain text instead
+		return text
+	}
+	return b.String()
+}
 
-	return content.String()
+// foldRunes lowercases each rune, keeping indexes aligned with the input.
+func foldRunes(runes []rune) []rune {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		folded[i] = unicode.ToLower(r)
+	}
+	return folded
 }
 
-func filterLogs(logs []Log, query, start, end string) []Log {
-	var result []Log
-	for _, log := range logs {
-		if query != "" && !strings.Contains(strings.ToLower(log.message), strings.ToLower(query)) {
-			continue
-		}
-		if start != "" && log.timestamp < start {
-			continue
+// indexRunes returns the index of the first occurrence of sub in s, or -1.
+func indexRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			return i
 		}
-		if end != "" && log.timestamp > end {
-			continue
+	}
+	return -1
+}
+
+// visibleColumns returns the configured columns, defaulting to showing the
+// source only when following a directory.
+func (m *model) visibleColumns() []string {
+	columns := []string{"timestamp", "message"}
+	switch {
+	case m.columns != nil:
+		columns = m.columns
+	case m.watcher != nil:
+		columns = []string{"timestamp", "source", "message"}
+	}
+	if m.showLevel && !slices.Contains(columns, "level") {
+		at := slices.Index(columns, "message")
+		if at < 0 {
+			at = len(columns)
 		}
-		result = append(result, log)
+		columns = slices.Insert(slices.Clone(columns), at, "level")
 	}
-	return result
+	if m.showDelta && !slices.Contains(columns, "delta") {
+		at := slices.Index(columns, "timestamp") + 1
+		columns = slices.Insert(slices.Clone(columns), at, "delta")
+	}
+	if m.hasIngest && m.columns == nil {
+		at := slices.Index(columns, "timestamp") + 1
+		columns = slices.Insert(slices.Clone(columns), at, "ingested")
+	}
+	return columns
 }
 
-func (m *model) clearFocusedFilter() {
-	switch m.focused {
-	case searchBoxFocused:
-		m.searchBox.SetValue("")
-	case startDateFocused:
-		m.startDate.SetValue("")
-	case endDateFocused:
-		m.endDate.SetValue("")
+// formatTimestamp shows sub-second timestamps at the configured precision and
+// leaves all others as they appeared in the log.
+func (m *model) formatTimestamp(log Log) string {
+	if log.timestamp == "" {
+		return noTimestamp
 	}
-	m.applyFilters()
+	if m.relativeTime && !log.at.IsZero() {
+		return formatRelative(time.Since(log.at))
+	}
+	// Date-only timestamps have no time of day to show on a 12-hour clock
+	twelveHour := m.clock12 && strings.Contains(log.timestamp, ":")
+	if log.at.IsZero() || log.at.Nanosecond() == 0 && !twelveHour {
+		return log.timestamp
+	}
+	layout := canonicalTimeFormat
+	if twelveHour {
+		layout = "2006-01-02 03:04:05"
+	}
+	if m.tsPrecision > 0 && log.at.Nanosecond() != 0 {
+		layout += "." + strings.Repeat("0", m.tsPrecision)
+	}
+	if twelveHour {
+		layout += " PM"
+	}
+	return log.at.Format(layout)
 }
 
-func (m *model) applyFilters() {
-	var logs []Log
-	switch m.activeTab {
-	case Errors:
-		logs = m.errors
-	case Warnings:
-		logs = m.warnings
-	case Information:
-		logs = m.info
+// formatRelative renders an age like "2m ago" or "in 5s".
+func formatRelative(d time.Duration) string {
+	suffix := " ago"
+	prefix := ""
+	if d < 0 {
+		d = -d
+		prefix, suffix = "in ", ""
 	}
-	m.filteredLogs = filterLogs(logs, m.searchBox.Value(), m.startDate.Value(), m.endDate.Value())
+	var amount string
+	switch {
+	case d < time.Minute:
+		amount = fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		amount = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		amount = fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		amount = fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return prefix + amount + suffix
 }
 
-func main() {
-	searchBox := textinput.New()
-	searchBox.Placeholder = "Enter keyword"
-	searchBox.Width = 30
+// relativeTickMsg refreshes relative timestamps outside follow mode.
+type relativeTickMsg time.Time
 
-	startDate := textinput.New()
-	startDate.Placeholder = "YYYY-MM-DD"
-	startDate.Width = 12
+// relativeRefresh is how often relative timestamps are recomputed.
+const relativeRefresh = 15 * time.Second
 
-	endDate := textinput.New()
-	endDate.Placeholder = "YYYY-MM-DD"
-	endDate.Width = 12
+func relativeTick() tea.Cmd {
+	return tea.Tick(relativeRefresh, func(t time.Time) tea.Msg {
+		return relativeTickMsg(t)
+	})
+}
+
+// cell renders one column of the i-th filtered log.
+func (m *model) cell(name string, i int) string {
+	log := m.filteredLogs[i]
+	switch name {
+	case "timestamp":
+		if m.collapseTimes && i > 0 && m.filteredLogs[i-1].timestamp == log.timestamp {
+			return ""
+		}
+		ts := m.formatTimestamp(log)
+		if m.dateOnly && !m.relativeTime && len(ts) > len(canonicalDateFormat) {
+			ts = ts[:len(canonicalDateFormat)]
+		}
+		return ts
+	case "level":
+		// The raw level tells apart levels that share a bucket, like crit and error
+		if log.level != "" && !log.promoted {
+			return log.level
+		}
+		return severityNames[log.severity]
+	case "source":
+		return log.source
+	case "ingested":
+		if log.ingested == "" {
+			return ""
+		}
+		return m.formatTimestamp(Log{timestamp: log.ingested, at: log.ingestedAt})
+	case "delta":
+		if i == 0 || log.at.IsZero() || m.filteredLogs[i-1].at.IsZero() {
+			return "—"
+		}
+		return formatDelta(log.at.Sub(m.filteredLogs[i-1].at))
+	}
+
+	message := m.normalize.apply(m.redact.apply(log.message))
+	if log.promoted {
+		message = "⇑ " + message
+	}
+	if m.outOfOrder[log.id] {
+		message = "↶ " + message
+	}
+	if m.markBefore(i) {
+		message = "┈ mark ┈ " + message
+	}
+	if m.categories[m.activeTab].mixed() {
+		message = severityGlyphs[log.severity] + " " + message
+		if gap := m.gapBefore(i); gap > 0 {
+			message = "⏱ gap " + formatGap(gap) + " · " + message
+		}
+	}
+	return message
+}
+
+func (m *model) initSummaryTable() {
+	columns := []table.Column{
+		{Title: "Count", Width: 6},
+		{Title: "First", Width: 20},
+		{Title: "Last", Width: 20},
+		{Title: "Message", Width: max(m.width-52, 10)},
+	}
+
+	rows := make([]table.Row, len(m.summaries))
+	for i, s := range m.summaries {
+		message := m.normalize.apply(m.redact.apply(s.pattern))
+		if m.comparing {
+			message = severityGlyphs[s.severity] + " " + message
+		}
+		rows[i] = table.Row{fmt.Sprint(s.count), s.first, s.last, message}
+	}
+
+	m.logTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithHeight(m.tableHeight()),
+		table.WithFocused(m.focused == logFocus),
+	)
+}
+
+// tableHeight fits the table into whatever the rest of the layout leaves.
+func (m *model) tableHeight() int {
+	if m.height == 0 {
+		return 10
+	}
+	// Title, tab bar, "Logs:" and "Help:" headings and the footer
+	used := 4 + 4 + 2 + 2 + lipgloss.Height(m.renderHelpFooter())
+	if m.filtersVisible {
+		used += 6
+		if m.noDates {
+			used -= 2
+		}
+	}
+	if m.showLegend {
+		used++
+	}
+	if m.showStats {
+		used += len(severityLabels)
+	}
+	if m.summaryPattern != "" {
+		used++
+	}
+	if m.sourceFilter != "" {
+		used++
+	}
+	if m.excludeBound() != "" {
+		used++
+	}
+	if m.batchOnly {
+		used++
+	}
+	if m.afterMark {
+		used++
+	}
+	if m.statusLine() != "" {
+		used += 2
+	}
+	if m.promptLine() != "" {
+		used++
+	}
+	return max(m.height-used, 3)
+}
+
+// doubleQuitWindow is how soon a second q must follow the first when quitting
+// takes a double press.
+const doubleQuitWindow = time.Second
+
+// quitKeys are the accepted --quit-key bindings.
+var quitKeys = []string{"q", "qq", "ctrl+c"}
+
+// quitKeyPressed reports whether key completes the configured quit binding,
+// hinting at the binding when q alone isn't enough.
+func (m *model) quitKeyPressed(key string) bool {
+	switch m.quitKey {
+	case "ctrl+c":
+		if key == "q" {
+			m.status = "Press Ctrl+C to quit"
+		}
+		return key == "ctrl+c"
+	case "qq":
+		if key != "q" {
+			return false
+		}
+		if time.Since(m.lastQKey) <= doubleQuitWindow {
+			return true
+		}
+		m.lastQKey = time.Now()
+		m.status = "Press q again to quit"
+		return false
+	}
+	return key == "q"
+}
+
+func (m model) quitLabel() string {
+	switch m.quitKey {
+	case "ctrl+c":
+		return "^C"
+	case "qq":
+		return "QQ"
+	}
+	return "Q"
+}
+
+// helpItem is one key hint in the footer.
+type helpItem struct {
+	key         string
+	description string
+}
+
+// helpItems returns the hints for whatever has focus, so the footer lists
+// only keys that do something right now.
+func (m model) helpItems() []helpItem {
+	apply, liveFilter := "Apply", "Live Filter: Off"
+	if m.liveFilter {
+		apply, liveFilter = "Done", "Live Filter: On"
+	}
+
+	switch m.focused {
+	case overwriteFocused:
+		return []helpItem{{"Y", "Overwrite"}, {"N", "Cancel"}}
+	case exportFocused:
+		return []helpItem{{"Enter", "Export"}, {"Esc", "Cancel"}}
+	case findFocused:
+		return []helpItem{{"Enter", "Find"}, {"Esc", "Cancel"}}
+	case searchBoxFocused:
+		return []helpItem{
+			{"Enter", apply},
+			{"Esc", "Clear"},
+			{"→", "Recall Cleared Search"},
+			{"^/$", "Anchor Prefix/Suffix"},
+			{"^X", "Regex Search"},
+			{"^L", liveFilter},
+		}
+	case startDateFocused, endDateFocused, excludeDateFocused:
+		return []helpItem{{"Enter", apply}, {"Esc", "Clear"}, {"^L", liveFilter}}
+	}
+
+	if m.confirmingQuit {
+		return []helpItem{{"Y", "Quit"}, {"N", "Keep Following"}}
+	}
+	if m.showDetail {
+		return []helpItem{{"Enter/Esc", "Close"}}
+	}
+	if m.showKeys {
+		return []helpItem{{"F1/Esc", "Close"}}
+	}
+	if m.showSummary && m.comparing {
+		return []helpItem{
+			{"↑/↓", "Move"},
+			{"Enter", "Show Matching Entries"},
+			{"S", "Error Summary"},
+			{"⇧B", "Back to Logs"},
+			{m.quitLabel(), "Exit"},
+		}
+	}
+	if m.showSummary {
+		return []helpItem{
+			{"↑/↓", "Move"},
+			{"Enter", "Show Matching Errors"},
+			{"S", "Back to Logs"},
+			{"⇧B", "Compare to Baseline"},
+			{m.quitLabel(), "Exit"},
+		}
+	}
+	var items []helpItem
+	for _, group := range m.keyGroups() {
+		for _, item := range group.items {
+			if footerKeys[item.description] {
+				items = append(items, item)
+			}
+		}
+	}
+	return append(items, helpItem{"F1", "All Keys"})
+}
+
+// footerKeys are the descriptions of the log view keys the footer shows;
+// F1 lists the rest.
+var footerKeys = map[string]bool{
+	"Exit":          true,
+	"Move":          true,
+	"Switch Tab":    true,
+	"Search":        true,
+	"Filter Panel":  true,
+	"Error Summary": true,
+	"Export":        true,
+	"Copy":          true,
+}
+
+// renderKeyGroups renders the F1 overlay: every log view key, by category.
+func (m model) renderKeyGroups() string {
+	width := m.width
+	if width == 0 {
+		width = 80
+	}
+	var sections []string
+	for _, group := range m.keyGroups() {
+		sections = append(sections, dayStyle.Render(group.name)+"\n"+renderHelpLines(group.items, width))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// helpGroup is a titled set of key hints for the F1 overlay.
+type helpGroup struct {
+	name  string
+	items []helpItem
+}
+
+// keyGroups lists the log view's keys by category. The F1 overlay shows it
+// all and the footer its footerKeys, so the two can't disagree.
+func (m model) keyGroups() []helpGroup {
+	liveFilter := "Live Filter: Off"
+	if m.liveFilter {
+		liveFilter = "Live Filter: On"
+	}
+	return []helpGroup{
+		{"Navigation", []helpItem{
+			{m.quitLabel(), "Exit"},
+			{"↑/↓", "Move"},
+			{"PgUp/PgDn", "Page"},
+			{"g/G", "Top/Bottom"},
+			{"W", "Wrap-Around"},
+			{"Tab", "Switch Tab"},
+			{"]/[", "Next/Prev Error"},
+			{"M", "Set/Clear Mark"},
+			{"'", "Jump to Mark"},
+			{"?", "Find"},
+			{"n/N", "Next/Prev Match"},
+			{"^N", "Cursor on Update"},
+		}},
+		{"Filtering", []helpItem{
+			{"/", "Search"},
+			{"^O", "Whole Words"},
+			{"^X", "Regex Search"},
+			{"P", "Filter Panel"},
+			{"F", "Start Date"},
+			{"E", "End Date"},
+			{"-", "Exclude Date"},
+			{"!", "Since Last Error"},
+			{"⇧Z/Alt+Z", "Zoom to Minute/Hour"},
+			{"</>", "Widen/Narrow Range"},
+			{"B", "Set Baseline Range"},
+			{"⇧B", "Compare to Baseline"},
+			{"=", "More Like This"},
+			{"⇧M", "Only After Mark"},
+			{"⇧D", "Newest Batch Only"},
+			{"1/2/3", "Toggle Errors/Warnings/Info"},
+			{"U", "Undo Filter"},
+			{"^L", liveFilter},
+		}},
+		{"View", []helpItem{
+			{"S", "Error Summary"},
+			{"L", "Legend"},
+			{"%", "Severity Stats"},
+			{"#", "Matches per Day"},
+			{"T", "Relative Time"},
+			{"H", "Highlight"},
+			{"C", "Collapse Times"},
+			{"Z", "Striped Rows"},
+			{"⇧S", "Errors First on Ties"},
+			{"⇧O", "Clock Check"},
+			{"+", "Elapsed Column"},
+			{"V", "Level Column"},
+			{"^T", "12/24-Hour Clock"},
+			{"⇧T", "Date/Full Timestamps"},
+			{"⇧I", "Event/Ingest Time"},
+			{"⇧R", "Recency Colors"},
+			{"^R", "Auto-Refresh"},
+		}},
+		{"Export", []helpItem{
+			{"Space", "Select"},
+			{"A", "Select All"},
+			{"I", "Invert Selection"},
+			{"X", "Export"},
+			{"^H", "HTML Report"},
+			{"Y", "Copy"},
+			{"⇧Y", "Copy as Command"},
+			{"^Y", "Copy Timestamp"},
+			{"O", "Open file:line"},
+			{"⇧W", "Copy Report"},
+		}},
+	}
+}
+
+func (m model) renderHelpFooter() string {
+	width := m.width
+	if width == 0 {
+		width = 80 // fallback width
+	}
+	return renderHelpLines(m.helpItems(), width)
+}
+
+// renderHelpLines lays items out in full-width lines, wrapping between items.
+func renderHelpLines(items []helpItem, width int) string {
+	separator := helpSeparatorStyle.Render(" | ")
+	indent := helpStyle.Render("  ")
+
+	// Pad each line to full width
+	var lines []string
+	line := indent
+	flush := func() {
+		if padding := width - lipgloss.Width(line); padding > 0 {
+			line += helpStyle.Render(strings.Repeat(" ", padding))
+		}
+		lines = append(lines, line)
+	}
+
+	// Break before an item that would overflow the terminal
+	for i, item := range items {
+		rendered := helpKeyStyle.Render(item.key) + helpStyle.Render(" "+item.description)
+		switch {
+		case lipgloss.Width(indent+rendered) > width:
+			// Too wide for a line of its own: wrap the description
+			if i > 0 {
+				flush()
+			}
+			line = indent + helpKeyStyle.Render(item.key)
+			room := width - lipgloss.Width(line) - 1
+			for j, part := range wrapText(item.description, room, width-lipgloss.Width(indent)) {
+				if j == 0 {
+					line += helpStyle.Render(" " + part)
+					continue
+				}
+				flush()
+				line = indent + helpStyle.Render(part)
+			}
+		case i == 0:
+			line += rendered
+		case lipgloss.Width(line+separator+rendered) > width:
+			flush()
+			line = indent + rendered
+		default:
+			line += separator + rendered
+		}
+	}
+	flush()
+
+	return strings.Join(lines, "\n")
+}
+
+// wrapText breaks text at spaces into lines of at most first columns for
+// the first line and rest for the others, splitting words too long for a
+// line of their own.
+func wrapText(text string, first, rest int) []string {
+	var parts []string
+	limit := first
+	if limit < 1 {
+		parts, limit = append(parts, ""), rest
+	}
+	current := ""
+	for _, word := range strings.Fields(text) {
+		for word != "" {
+			width := runewidth.StringWidth(word)
+			switch {
+			case current == "" && width <= limit:
+				current, word = word, ""
+			case current != "" && runewidth.StringWidth(current)+1+width <= limit:
+				current, word = current+" "+word, ""
+			case current != "":
+				parts, current, limit = append(parts, current), "", rest
+			default:
+				cut := runewidth.Truncate(word, limit, "")
+				if cut == "" {
+					_, size := utf8.DecodeRuneInString(word)
+					cut = word[:size]
+				}
+				parts, word, limit = append(parts, cut), word[len(cut):], rest
+			}
+		}
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg, logsMsg:
+		m.lastActivity = time.Now()
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.focused == overwriteFocused {
+			m.confirmOverwrite(msg.String() == "y")
+			return m, nil
+		}
+		if m.showDetail {
+			switch msg.String() {
+			case "enter", "esc", "q":
+				m.showDetail = false
+			}
+			return m, nil
+		}
+		if m.showKeys {
+			switch msg.String() {
+			case "f1", "esc", "q":
+				m.showKeys = false
+			}
+			return m, nil
+		}
+		if m.confirmingQuit {
+			if msg.String() == "y" {
+				return m, tea.Quit
+			}
+			m.confirmingQuit = false
+			m.status = ""
+			m.setCursor(len(m.filteredLogs) - 1)
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "q", "ctrl+c":
+			if m.focused == logFocus && m.quitKeyPressed(msg.String()) {
+				// Quitting mid-follow loses context; static views exit at once
+				if m.live != nil && !m.loadOnly && !m.showSummary && m.following() {
+					m.confirmingQuit = true
+					m.status = "Still following. Quit? (y/n)"
+					return m, nil
+				}
+				return m, tea.Quit
+			}
+		case "ctrl+r":
+			if m.filePath == "" {
+				m.status = "Auto-refresh needs a local --file"
+				return m, nil
+			}
+			m.autoRefresh = !m.autoRefresh
+			m.refreshSeq++
+			if m.autoRefresh {
+				return m, m.scheduleRefresh()
+			}
+			return m, nil
+		case "ctrl+l":
+			m.liveFilter = !m.liveFilter
+			return m, nil
+		case "f1":
+			if m.focused == logFocus {
+				m.showKeys = true
+				return m, nil
+			}
+		case "ctrl+x":
+			if m.focused == logFocus || m.focused == searchBoxFocused {
+				m.toggleRegexSearch()
+				return m, nil
+			}
+		case "tab":
+			m.switchTab((m.activeTab + 1) % len(m.categories))
+			m.showSummary = false
+			m.applyFilters() // Update filtered logs for new tab
+			m.initLogTable() // Reinitialize table with new data
+			m.restoreCursor()
+		case "shift+tab":
+			m.switchTab((m.activeTab + len(m.categories) - 1) % len(m.categories))
+			m.showSummary = false
+			m.applyFilters() // Update filtered logs for new tab
+			m.initLogTable() // Reinitialize table with new data
+			m.restoreCursor()
+		case "]", "[":
+			if m.focused == logFocus && !m.showSummary {
+				m.jumpToError(msg.String() == "]")
+			}
+		case "s":
+			if m.focused == logFocus {
+				m.showSummary = !m.showSummary || m.comparing
+				m.comparing = false
+				if m.showSummary {
+					errors, query := m.errors, m.searchBox.Value()
+					switch {
+					case m.queryLang && query != "":
+						errors, query = m.matchExpression(errors, query), ""
+					case m.regexSearch && query != "":
+						errors, query = m.matchRegex(errors), ""
+					}
+					m.summaries = summarizeErrors(filterLogs(errors, query, m.wholeWord, m.startBound(), m.endBound(), m.excludeBound()))
+				}
+				m.initLogTable()
+			}
+		case "l":
+			if m.focused == logFocus {
+				m.showLegend = !m.showLegend
+			}
+		case "%":
+			if m.focused == logFocus && !m.showSummary {
+				m.showStats = !m.showStats
+				m.resizeTable()
+			}
+		case "#":
+			if m.focused == logFocus && !m.showSummary {
+				m.showMatchDays = !m.showMatchDays
+				m.applyFilters()
+			}
+		case "=", "@":
+			if m.focused == logFocus && !m.showSummary {
+				m.filterBySelected(msg.String() == "@")
+			}
+		case " ":
+			if m.focused == logFocus && !m.showSummary {
+				m.toggleSelected()
+				return m, nil
+			}
+		case "a":
+			if m.focused == logFocus && !m.showSummary {
+				m.selectAllVisible()
+			}
+		case "i":
+			if m.focused == logFocus && !m.showSummary {
+				m.invertSelection()
+			}
+		case "x":
+			if m.focused == logFocus {
+				m.promptExport()
+			}
+		case "y":
+			if m.focused == logFocus {
+				m.copySelection()
+			}
+		case "Y":
+			if m.focused == logFocus {
+				m.copyCommand()
+			}
+		case "ctrl+y":
+			if m.focused == logFocus && !m.showSummary {
+				m.copyTimestamp()
+			}
+		case "<", ">":
+			if m.focused == logFocus {
+				if m.datesDisabled() {
+					return m, nil
+				}
+				m.adjustRange(msg.String() == "<")
+			}
+		case "!":
+			if m.focused == logFocus {
+				if m.datesDisabled() {
+					return m, nil
+				}
+				m.sinceLastError()
+			}
+		case "u":
+			if m.focused == logFocus {
+				m.undoFilter()
+				return m, nil
+			}
+		case "h":
+			if m.focused == logFocus && !m.showSummary {
+				m.highlight = !m.highlight
+				cursor := m.logTable.Cursor()
+				m.initLogTable()
+				m.setCursor(cursor)
+			}
+		case "W":
+			if m.focused == logFocus {
+				m.copyReport()
+			}
+		case "w":
+			if m.focused == logFocus {
+				m.wrapAround = !m.wrapAround
+				m.status = "Wrap-around off"
+				if m.wrapAround {
+					m.status = "Wrap-around on"
+				}
+			}
+		case "ctrl+o":
+			if m.focused == logFocus || m.focused == searchBoxFocused {
+				m.wholeWord = !m.wholeWord
+				if m.regexSearch {
+					m.compileSearch()
+				}
+				m.applyFilters()
+				m.initLogTable()
+				return m, nil
+			}
+		case "ctrl+h":
+			if m.focused == logFocus && !m.showSummary {
+				m.exportHTML()
+			}
+		case "Z", "alt+z":
+			if m.focused == logFocus && !m.showSummary && !m.datesDisabled() {
+				unit := time.Minute
+				if msg.String() == "alt+z" {
+					unit = time.Hour
+				}
+				m.zoom(unit)
+			}
+		case "ctrl+n":
+			if m.focused == logFocus {
+				m.cycleOnUpdate()
+			}
+		case "z":
+			if m.focused == logFocus {
+				m.zebra = !m.zebra
+			}
+		case "S":
+			if m.focused == logFocus && !m.showSummary {
+				m.toggleSeverityTies()
+			}
+		case "o":
+			if m.focused == logFocus && !m.showSummary {
+				return m, m.openSource()
+			}
+		case "I":
+			if m.focused == logFocus && !m.showSummary {
+				m.toggleIngestTime()
+			}
+		case "m":
+			if m.focused == logFocus && !m.showSummary {
+				m.setMark()
+			}
+		case "'":
+			if m.focused == logFocus && !m.showSummary {
+				m.jumpToMark()
+			}
+		case "M":
+			if m.focused == logFocus && !m.showSummary {
+				m.toggleAfterMark()
+			}
+		case "b":
+			if m.focused == logFocus && !m.showSummary {
+				// b would otherwise also page up the table
+				m.setBaseline()
+				return m, nil
+			}
+		case "B":
+			if m.focused == logFocus {
+				m.toggleCompare()
+			}
+		case "D":
+			if m.focused == logFocus && !m.showSummary {
+				m.toggleBatchOnly()
+			}
+		case "1", "2", "3":
+			if m.focused == logFocus && !m.showSummary {
+				m.toggleSeverity(int(msg.String()[0] - '1'))
+			}
+		case "R":
+			if m.focused == logFocus && !m.showSummary {
+				m.recency = !m.recency
+				m.resizeTable()
+				if m.recency && !m.relativeTime && m.watcher == nil {
+					return m, relativeTick()
+				}
+				return m, nil
+			}
+		case "ctrl+t":
+			if m.focused == logFocus && !m.showSummary {
+				m.clock12 = !m.clock12
+				m.resizeTable()
+			}
+		case "T":
+			if m.focused == logFocus && !m.showSummary {
+				m.timeDetail = !m.timeDetail
+				m.resizeTable()
+			}
+		case "v":
+			if m.focused == logFocus && !m.showSummary {
+				m.showLevel = !m.showLevel
+				m.resizeTable()
+			}
+		case "+":
+			if m.focused == logFocus && !m.showSummary {
+				m.showDelta = !m.showDelta
+				m.resizeTable()
+			}
+		case "O":
+			if m.focused == logFocus && !m.showSummary {
+				m.clockCheck = !m.clockCheck
+				m.status = ""
+				m.checkClock()
+				m.resizeTable()
+			}
+		case "c":
+			if m.focused == logFocus && !m.showSummary {
+				m.collapseTimes = !m.collapseTimes
+				m.resizeTable()
+			}
+		case "t":
+			if m.focused == logFocus && !m.showSummary {
+				m.relativeTime = !m.relativeTime
+				cursor := m.logTable.Cursor()
+				m.initLogTable()
+				m.setCursor(cursor)
+				if m.relativeTime && m.watcher == nil {
+					return m, relativeTick()
+				}
+				return m, nil
+			}
+		case "p":
+			if m.focused == logFocus {
+				m.filtersVisible = !m.filtersVisible
+				cursor := m.logTable.Cursor()
+				m.initLogTable() // Resize table to the space reclaimed
+				m.setCursor(cursor)
+			}
+		case "?":
+			if m.focused == logFocus && !m.showSummary {
+				m.focused = findFocused
+				m.findBox.SetValue(m.findQuery)
+				m.findBox.CursorEnd()
+				m.findBox.Focus()
+				m.resizeTable()
+				return m, nil
+			}
+		case "n", "N":
+			if m.focused == logFocus && !m.showSummary {
+				m.findNext(msg.String() == "n")
+				return m, nil
+			}
+		case "right":
+			// Recall the last cleared search from the placeholder
+			if m.focused == searchBoxFocused && m.searchBox.Value() == "" &&
+				m.searchBox.Placeholder != searchPlaceholder {
+				m.searchBox.SetValue(m.searchBox.Placeholder)
+				m.searchBox.CursorEnd()
+				return m, nil
+			}
+		case "/":
+			if m.focused == logFocus {
+				m.filtersVisible = true
+				m.focused = searchBoxFocused
+				m.searchBox.Focus()
+				m.startDate.Blur()
+				m.endDate.Blur()
+			}
+		case "f":
+			if m.focused == logFocus {
+				if m.datesDisabled() {
+					return m, nil
+				}
+				m.filtersVisible = true
+				m.focused = startDateFocused
+				m.startDate.Focus()
+				m.searchBox.Blur()
+				m.endDate.Blur()
+			}
+		case "e":
+			if m.focused == logFocus {
+				if m.datesDisabled() {
+					return m, nil
+				}
+				m.filtersVisible = true
+				m.focused = endDateFocused
+				m.endDate.Focus()
+				m.searchBox.Blur()
+				m.startDate.Blur()
+			}
+		case "-":
+			if m.focused == logFocus {
+				if m.datesDisabled() {
+					return m, nil
+				}
+				m.filtersVisible = true
+				m.focused = excludeDateFocused
+				m.excludeDate.Focus()
+				m.searchBox.Blur()
+				m.startDate.Blur()
+				m.endDate.Blur()
+			}
+		case "esc":
+			if m.focused == exportFocused {
+				m.status = "Export cancelled"
+			}
+			m.clearFocusedFilter()
+			m.focused = logFocus
+			m.searchBox.Blur()
+			m.startDate.Blur()
+			m.endDate.Blur()
+			m.excludeDate.Blur()
+			m.exportBox.Blur()
+			m.findBox.Blur()
+			m.initLogTable() // Reinitialize table after clearing filter
+		case "enter":
+			if m.focused == logFocus && m.showSummary {
+				// Filter the pattern's severity tab down to the selected pattern
+				if i := m.logTable.Cursor(); i >= 0 && i < len(m.summaries) {
+					s := m.summaries[i]
+					m.summaryPattern = s.pattern
+					m.showSummary, m.comparing = false, false
+					tab := m.findTab(func(c category) bool {
+						sev, ok := c.severity()
+						return ok && sev == s.severity
+					})
+					if tab >= 0 {
+						m.switchTab(tab)
+					}
+					m.applyFilters()
+					m.initLogTable()
+				}
+			} else if m.focused == logFocus && len(m.filteredLogs) > 0 {
+				m.showDetail = true
+				return m, nil
+			} else if m.focused == exportFocused {
+				m.submitExport()
+				return m, nil
+			} else if m.focused == findFocused {
+				m.findQuery = m.findBox.Value()
+				m.focused = logFocus
+				m.findBox.Blur()
+				m.resizeTable()
+				m.findNext(true)
+				return m, nil
+			} else if m.focused == searchBoxFocused || m.focused == startDateFocused || m.focused == endDateFocused || m.focused == excludeDateFocused {
+				if m.focused == searchBoxFocused && m.searchBox.Value() != "" {
+					m.searchBox.Placeholder = searchPlaceholder
+				}
+				m.applyFilters()
+				m.initLogTable() // Reinitialize table after applying filters
+				m.focused = logFocus
+			}
+		}
+
+		// Handle table navigation when focused on logs
+		if m.focused == logFocus {
+			var tableMsg tea.Msg = msg
+			cursor := m.logTable.Cursor()
+			if last := len(m.logTable.Rows()) - 1; m.wrapAround && last > 0 {
+				switch {
+				case cursor == last && key.Matches(msg, m.logTable.KeyMap.LineDown):
+					m.setCursor(0)
+					return m, nil
+				case cursor == 0 && key.Matches(msg, m.logTable.KeyMap.LineUp):
+					m.setCursor(last)
+					return m, nil
+				}
+			}
+			m.logTable, cmd = m.logTable.Update(tableMsg)
+			if m.logTable.Cursor() != cursor {
+				m.setCursor(m.logTable.Cursor())
+			}
+			return m, cmd
+		}
+
+	case logsMsg:
+		if m.loading {
+			m.recordFileKeys(msg)
+		}
+		if m.loading && m.loadOnly {
+			m.addLogs(msg)
+		} else {
+			m.addBatch(msg)
+		}
+		m.afterUpdate()
+		return m, waitForLogs(m.live)
+
+	case loadDoneMsg:
+		m.finishProgressiveLoad()
+		m.refreshLogs()
+		if m.live == nil {
+			return m, nil
+		}
+		return m, waitForLogs(m.live)
+
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.status = "Editor failed: " + msg.err.Error()
+		}
+		return m, nil
+
+	case sourceErrMsg:
+		m.status = "Source error: " + msg.err.Error()
+		return m, waitForLogs(m.live)
+
+	case pollMsg:
+		if logs := m.watcher.poll(); len(logs) > 0 {
+			m.addBatch(logs)
+			m.afterUpdate()
+		} else if m.relativeTime || m.recency {
+			m.refreshLogs()
+		}
+		return m, pollEvery(m.pollInterval)
+
+	case refreshMsg:
+		if !m.autoRefresh || msg.seq != m.refreshSeq {
+			return m, nil
+		}
+		if m.loading {
+			// Reloading now would duplicate the entries still streaming in
+			return m, m.scheduleRefresh()
+		}
+		if added, removed, err := m.reloadFile(); err != nil {
+			m.status = "Reload failed: " + err.Error()
+		} else if added > 0 || removed > 0 {
+			m.afterUpdate()
+		}
+		return m, m.scheduleRefresh()
+
+	case idleMsg:
+		if time.Since(m.lastActivity) >= m.idleTimeout {
+			return m, tea.Quit
+		}
+		return m, m.idleCheck()
+
+	case rateTickMsg:
+		return m, rateTick()
+
+	case relativeTickMsg:
+		if !m.relativeTime && !m.recency {
+			return m, nil
+		}
+		m.refreshLogs()
+		return m, relativeTick()
+
+	case liveFilterMsg:
+		if msg.seq == m.filterSeq {
+			m.applyFilters()
+			m.initLogTable()
+		}
+		return m, nil
+
+	case tea.MouseMsg:
+		if m.focused == logFocus && !m.showSummary {
+			m.dragColumn(msg)
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.initLogTable() // Reinitialize table with new dimensions
+		if m.noAltScreen {
+			// Clearing would wipe the scrollback we're trying to keep
+			return m, nil
+		}
+		return m, tea.ClearScreen
+	}
+
+	before := m.filterValues()
+	switch m.focused {
+	case searchBoxFocused:
+		m.searchBox, cmd = m.searchBox.Update(msg)
+	case startDateFocused:
+		m.startDate, cmd = m.startDate.Update(msg)
+	case endDateFocused:
+		m.endDate, cmd = m.endDate.Update(msg)
+	case excludeDateFocused:
+		m.excludeDate, cmd = m.excludeDate.Update(msg)
+	case exportFocused:
+		m.exportBox, cmd = m.exportBox.Update(msg)
+	case findFocused:
+		m.findBox, cmd = m.findBox.Update(msg)
+	}
+
+	m.searchQuery = m.searchBox.Value()
+	if m.regexSearch && m.focused == searchBoxFocused {
+		m.compileSearch()
+	}
+	if m.liveFilter && m.filterValues() != before {
+		m.filterSeq++
+		seq := m.filterSeq
+		return m, tea.Batch(cmd, tea.Tick(filterDebounce, func(time.Time) tea.Msg {
+			return liveFilterMsg{seq: seq}
+		}))
+	}
+	return m, cmd
+}
+
+// filterValues snapshots the filter inputs so edits can be detected.
+func (m *model) filterValues() [4]string {
+	return [4]string{m.searchBox.Value(), m.startDate.Value(), m.endDate.Value(), m.excludeDate.Value()}
+}
+
+func (m model) View() string {
+	content := strings.Builder{}
+
+	// Title
+	content.WriteString(m.renderTitle() + "\n\n")
+
+	// Tab bar
+	tabs := make([]string, len(m.categories))
+	for i, c := range m.categories {
+		label := c.name
+		if i < len(m.tabMatches) {
+			label += fmt.Sprintf(" (%d/%d)", m.tabMatches[i], m.tabTotals[i])
+		}
+		tabs[i] = tabStyle(c, i == m.activeTab).Render(label)
+	}
+	tabBar := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+	content.WriteString(tabBar + "\n")
+	if m.showLegend {
+		content.WriteString(renderLegend() + "\n")
+	}
+	if m.showStats {
+		content.WriteString(m.renderStats() + "\n")
+	}
+	content.WriteString("\n")
+
+	// Search and date filters
+	if m.filtersVisible {
+		search := "Search: " + m.searchBox.View()
+		if m.regexSearch && m.searchBox.Value() != "" {
+			if m.regexErr != nil {
+				search += " " + invalidStyle.Render("✖ invalid regex")
+			} else {
+				search += " " + validStyle.Render("✓")
+			}
+		}
+		if m.wholeWord {
+			search += "  [whole words]"
+		}
+		content.WriteString(search + "\n\n")
+		label := layoutLabel.Replace(m.dateFormat)
+		if m.noDates {
+			content.WriteString(noDatesNote + "\n\n")
+		} else {
+			content.WriteString("Start Date (" + label + "): " + m.startDate.View() + m.dateHint(m.startDate.Value()) + "\n")
+			content.WriteString("End Date (" + label + "): " + m.endDate.View() + m.dateHint(m.endDate.Value()) + "\n")
+			content.WriteString("Exclude Date (" + label + "): " + m.excludeDate.View() + m.dateHint(m.excludeDate.Value()) + "\n\n")
+		}
+	}
+
+	if m.summaryPattern != "" {
+		content.WriteString("Pattern: " + m.summaryPattern + " (Esc to clear)\n")
+	}
+	if m.sourceFilter != "" {
+		content.WriteString("Source: " + m.sourceFilter + " (Esc to clear)\n")
+	}
+	if exclude := m.excludeBound(); exclude != "" {
+		content.WriteString("Excluding: " + exclude + " (Esc to clear)\n")
+	}
+	if m.batchOnly {
+		content.WriteString(m.batchHeader() + "\n")
+	}
+	if m.afterMark {
+		content.WriteString("From mark: " + m.markLabel() + " (⇧M to show all)\n")
+	}
+
+	// Log table
+	switch {
+	case m.showDetail:
+		content.WriteString("\nDetail:\n")
+		content.WriteString(m.renderDetail(m.width))
+	case m.showKeys:
+		content.WriteString("\nKeys:\n")
+		content.WriteString(m.renderKeyGroups())
+	case m.showSummary && m.comparing:
+		content.WriteString(fmt.Sprintf("\nNew in %s vs baseline %s:\n",
+			rangeLabel(m.startBound(), m.endBound()), rangeLabel(m.baseline[0], m.baseline[1])))
+		content.WriteString(m.renderTableArea())
+	case m.showSummary:
+		content.WriteString("\nError Summary:\n")
+		content.WriteString(m.renderTableArea())
+	default:
+		heading := "Logs:"
+		if day := m.dayHeader(); day != "" {
+			heading += " " + dayStyle.Render(day)
+		}
+		content.WriteString("\n" + heading + "\n")
+		content.WriteString(m.renderTableArea())
+	}
+
+	if prompt := m.promptLine(); prompt != "" {
+		content.WriteString("\n" + prompt)
+	}
+	if status := m.statusLine(); status != "" {
+		content.WriteString("\n" + status + "\n")
+	}
+
+	// Help table
+	content.WriteString("\nHelp:\n")
+	content.WriteString(m.renderHelpFooter())
+
+	return content.String()
+}
+
+// promptLine renders the one-line prompt below the table, if one is open.
+func (m model) promptLine() string {
+	switch m.focused {
+	case exportFocused, overwriteFocused:
+		return "Export to: " + m.exportBox.View()
+	case findFocused:
+		return "Find: " + m.findBox.View()
+	}
+	return ""
+}
+
+// statusLine combines the follow indicator with the latest status message.
+func (m model) statusLine() string {
+	var parts []string
+	if m.origin != "" && !m.loadedAt.IsZero() {
+		layout := "15:04:05"
+		if m.clock12 {
+			layout = "3:04:05 PM"
+		}
+		parts = append(parts, m.origin+" · loaded "+m.loadedAt.Format(layout))
+	}
+	if m.loading {
+		parts = append(parts, followStyle.Render(fmt.Sprintf("Loading… %d lines", len(m.errors)+len(m.warnings)+len(m.info))))
+	}
+	if m.live != nil && !m.loadOnly && !m.showSummary {
+		if m.following() {
+			parts = append(parts, followStyle.Render("FOLLOWING"))
+		} else {
+			parts = append(parts, pausedStyle.Render(fmt.Sprintf("PAUSED@%d", m.logTable.Cursor()+1)))
+		}
+	}
+	if m.live != nil && !m.loadOnly {
+		parts = append(parts, m.rateLabel())
+	}
+	if m.autoRefresh {
+		parts = append(parts, followStyle.Render("AUTO-REFRESH "+m.refreshInterval.String()))
+	}
+	if set := m.severitySet(); set != "" {
+		parts = append(parts, set)
+	}
+	if m.queryLang && m.queryErr != nil && m.searchBox.Value() != "" {
+		parts = append(parts, "Query error: "+m.queryErr.Error())
+	}
+	if m.regexSearch && m.regexErr != nil && m.searchBox.Value() != "" {
+		parts = append(parts, "Regex error: "+m.regexErr.Error())
+	}
+	if len(m.matchDays) > 0 {
+		parts = append(parts, m.matchDaysLabel())
+	}
+	if m.unknownLevels > 0 {
+		parts = append(parts, fmt.Sprintf("Unknown levels: %d (as %s)", m.unknownLevels, severityNames[unknownSeverity]))
+	}
+	if m.status != "" {
+		parts = append(parts, m.status)
+	}
+	if m.debug {
+		parts = append(parts, fmt.Sprintf("load %s / %d rows · filter %s / %d rows",
+			formatMillis(m.loadTime), m.loadRows, formatMillis(m.filterTime), len(m.filteredLogs)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// visibleRows is how many rows fit below the table header.
+func (m *model) visibleRows() int {
+	return max(m.tableHeight()-2, 1)
+}
+
+func (m *model) needsScrollbar() bool {
+	return len(m.filteredLogs) > m.visibleRows()
+}
+
+// renderScrollbar draws a track beside the table rows whose thumb shows the
+// size and position of the visible window within the filtered logs.
+func (m model) renderScrollbar() string {
+	rows, total := m.visibleRows(), len(m.filteredLogs)
+	thumb := max(rows*rows/total, 1)
+	top := 0
+	if total > 1 {
+		top = (rows - thumb) * m.logTable.Cursor() / (total - 1)
+	}
+
+	// Blank lines to line the track up with the rows, past the header
+	lines := []string{" ", " "}
+	for i := 0; i < rows; i++ {
+		if i >= top && i < top+thumb {
+			lines = append(lines, scrollThumbStyle.Render("█"))
+		} else {
+			lines = append(lines, scrollTrackStyle.Render("│"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripeRows gives every other row of the rendered table a subtle
+// background. The table can't style whole rows itself, so the stripes go on
+// its output, counted from the cursor's row so they stay with their rows as
+// it scrolls. Resets within a row re-apply the background, keeping severity
+// and match colors intact.
+func (m model) stripeRows(view string) string {
+	on, _, _ := strings.Cut(stripeStyle.Render("x"), "x")
+	selected, _, _ := strings.Cut(table.DefaultStyles().Selected.Render("x"), "x")
+	if on == "" || selected == "" {
+		// No colors to draw with
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	cursorLine := slices.IndexFunc(lines, func(line string) bool {
+		return strings.HasPrefix(line, selected)
+	})
+	if cursorLine < 0 {
+		return view
+	}
+	const reset = "\x1b[0m"
+	rows := len(m.logTable.Rows())
+	// The first line is the header
+	for i := 1; i < len(lines); i++ {
+		row := m.logTable.Cursor() + i - cursorLine
+		if i == cursorLine || row < 0 || row >= rows || row%2 == 0 {
+			continue
+		}
+		lines[i] = on + strings.ReplaceAll(lines[i], reset, reset+on) + reset
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatMillis renders d in milliseconds at microsecond resolution.
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d.Microseconds())/1000)
+}
+
+// dayHeader names the day of the row under the cursor when the shown rows
+// span several days. It sits above the table, so the day stays in view
+// however far into it the table scrolls.
+func (m model) dayHeader() string {
+	if len(m.filteredLogs) == 0 {
+		return ""
+	}
+	// Rows are in time order, undated ones last
+	first, last := m.filteredLogs[0].at, time.Time{}
+	for i := len(m.filteredLogs) - 1; i >= 0 && last.IsZero(); i-- {
+		last = m.filteredLogs[i].at
+	}
+	if first.IsZero() || first.Format(canonicalDateFormat) == last.Format(canonicalDateFormat) {
+		return ""
+	}
+	at := m.filteredLogs[min(max(m.logTable.Cursor(), 0), len(m.filteredLogs)-1)].at
+	if at.IsZero() {
+		return "── " + noTimestamp + " ──"
+	}
+	return "── " + at.Format("Mon "+canonicalDateFormat) + " ──"
+}
+
+// twoPaneWidth is the terminal width from which the selected entry's detail
+// sits beside the log table rather than replacing it on Enter.
+const twoPaneWidth = 160
+
+// twoPane reports whether the detail pane is shown beside the table.
+func (m model) twoPane() bool {
+	return m.width >= twoPaneWidth && !m.showSummary
+}
+
+// tableWidth is the width the log table is laid out in: all of the
+// terminal, or the left three fifths of it beside the detail pane.
+func (m model) tableWidth() int {
+	if m.twoPane() {
+		return m.width * 3 / 5
+	}
+	return m.width
+}
+
+// renderTableArea shows the table, or a message explaining why it's empty.
+func (m model) renderTableArea() string {
+	var msg string
+	switch {
+	case len(m.errors)+len(m.warnings)+len(m.info) == 0:
+		msg = "No logs loaded"
+	case m.showSummary && m.comparing && len(m.summaries) == 0:
+		msg = "No errors or warnings new since the baseline"
+	case m.showSummary && len(m.summaries) == 0:
+		msg = "No errors to summarize\nPress Esc in a filter field to clear it"
+	case !m.showSummary && len(m.filteredLogs) == 0:
+		msg = "No matching logs\nPress Esc in a filter field to clear it, or Tab to try another tab"
+	default:
+		view := m.logTable.View()
+		if m.zebra {
+			view = m.stripeRows(view)
+		}
+		if !m.showSummary && m.needsScrollbar() {
+			view = lipgloss.JoinHorizontal(lipgloss.Top, view, " ", m.renderScrollbar())
+		}
+		if m.twoPane() {
+			view = lipgloss.JoinHorizontal(lipgloss.Top, view, " ", m.renderDetail(m.width-lipgloss.Width(view)-1))
+		}
+		return view
+	}
+	return lipgloss.Place(max(m.width, lipgloss.Width(msg)), m.tableHeight(),
+		lipgloss.Center, lipgloss.Center, emptyStyle.Render(msg))
+}
+
+// renderDetail shows the entry under the cursor in full, with every field of
+// a structured entry, in the space the table would take.
+func (m model) renderDetail(width int) string {
+	i := m.logTable.Cursor()
+	if i < 0 || i >= len(m.filteredLogs) {
+		return ""
+	}
+	log := m.filteredLogs[i]
+	width = max(width, 40) - 4 // border and padding
+
+	lines := []string{
+		"Timestamp: " + m.formatTimestamp(log),
+		"Level:     " + severityNames[log.severity],
+	}
+	if log.source != "" {
+		lines = append(lines, "Source:    "+log.source)
+	}
+	// Highlight every match, as in the table, before wrapping
+	message := log.text()
+	if log.raw != "" {
+		message = log.raw
+	}
+	message = m.redact.apply(message)
+	if query, _, _ := parseAnchors(m.searchBox.Value()); m.highlight && !m.queryLang && !m.regexSearch && query != "" {
+		message = highlightMatches(message, query, m.wholeWord, lipgloss.NewStyle(), matchStyle, math.MaxInt)
+	}
+	lines = append(lines, "", lipgloss.NewStyle().Width(width).Render(message))
+
+	if len(log.fields) > 0 {
+		keys := make([]string, 0, len(log.fields))
+		keyWidth := 0
+		for key := range log.fields {
+			keys = append(keys, key)
+			keyWidth = max(keyWidth, len(key))
+		}
+		sort.Strings(keys)
+		lines = append(lines, "", "Fields:")
+		for _, key := range keys {
+			lines = append(lines, fmt.Sprintf("  %-*s  %s", keyWidth, key, m.redact.apply(log.fields[key])))
+		}
+	}
+
+	// Keep to the table's height so the rest of the layout doesn't move
+	height := m.tableHeight()
+	text := strings.Join(lines, "\n")
+	if rows := strings.Split(text, "\n"); len(rows) > height-2 {
+		text = strings.Join(rows[:max(height-2, 1)], "\n")
+	}
+	return detailStyle.Width(width + 2).Height(height - 2).Render(text)
+}
+
+// renderTitle centers the title with live severity counts, dropping the
+// counts when the terminal is too narrow to fit them.
+func (m model) renderTitle() string {
+	counts := strings.Join([]string{
+		errorCountStyle.Render(fmt.Sprintf("%d errors", len(m.errors))),
+		warningCountStyle.Render(fmt.Sprintf("%d warnings", len(m.warnings))),
+		infoCountStyle.Render(fmt.Sprintf("%d info", len(m.info))),
+	}, " ")
+	title := titleStyle.Render("System Log Analyzer — " + counts)
+	if m.width > 0 && lipgloss.Width(title) > m.width {
+		title = titleStyle.Render("System Log Analyzer")
+	}
+	return lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title)
+}
+
+// tabStyle outlines a single-severity tab in its severity's color, brighter
+// when active. Mixed tabs keep the default border.
+func tabStyle(c category, active bool) lipgloss.Style {
+	sev, ok := c.severity()
+	switch {
+	case active && ok:
+		return activeTab.Foreground(severityColors[sev]).BorderForeground(severityColors[sev])
+	case active:
+		return activeTab
+	case ok:
+		return tab.BorderForeground(severityDimColors[sev])
+	}
+	return tab
+}
+
+// renderLegend describes the color and glyph used for each severity.
+func renderLegend() string {
+	items := make([]string, len(severityGlyphs))
+	for sev, glyph := range severityGlyphs {
+		style := lipgloss.NewStyle().Foreground(severityColors[sev])
+		items[sev] = style.Render(glyph + " " + severityLabels[sev])
+	}
+	return "Legend: " + strings.Join(items, "  ")
+}
+
+// statsBarWidth is the length of a full bar in the stats panel.
+const statsBarWidth = 20
+
+// renderStats breaks the shown rows down by severity, one line each with
+// a bar, a percentage and a count.
+func (m model) renderStats() string {
+	var counts [3]int
+	for _, log := range m.filteredLogs {
+		counts[log.severity]++
+	}
+	total := len(m.filteredLogs)
+	lines := make([]string, len(severityLabels))
+	for sev, label := range severityLabels {
+		share := 0.0
+		if total > 0 {
+			share = float64(counts[sev]) / float64(total)
+		}
+		filled := int(math.Round(share * statsBarWidth))
+		bar := lipgloss.NewStyle().Foreground(severityColors[sev]).Render(strings.Repeat("█", filled)) +
+			scrollTrackStyle.Render(strings.Repeat("░", statsBarWidth-filled))
+		lines[sev] = fmt.Sprintf("%-12s %s %3.0f%%  %d", label, bar, share*100, counts[sev])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseAnchors strips a leading "^" and trailing "$" from a search query,
+// reporting which were present.
+func parseAnchors(query string) (term string, prefix, suffix bool) {
+	term = query
+	if strings.HasPrefix(term, "^") {
+		term, prefix = term[1:], true
+	}
+	if strings.HasSuffix(term, "$") && term != "" {
+		term, suffix = term[:len(term)-1], true
+	}
+	return term, prefix, suffix
+}
+
+// matchQuery reports whether message contains query, case-insensitively.
+// "^term" must start the message and "term$" must end it. With wholeWord
+// the term must not run into letters, digits or underscores either side.
+func matchQuery(message, query string, wholeWord bool) bool {
+	term, prefix, suffix := parseAnchors(query)
+	message, term = strings.ToLower(message), strings.ToLower(term)
+	if wholeWord {
+		return matchWord([]rune(message), []rune(term), prefix, suffix)
+	}
+	switch {
+	case prefix && suffix:
+		return message == term
+	case prefix:
+		return strings.HasPrefix(message, term)
+	case suffix:
+		return strings.HasSuffix(message, term)
+	}
+	return strings.Contains(message, term)
+}
+
+// matchWord is matchQuery for whole words, on lowercased runes.
+func matchWord(message, term []rune, prefix, suffix bool) bool {
+	switch {
+	case prefix && suffix:
+		return slices.Equal(message, term)
+	case prefix:
+		return len(message) >= len(term) && slices.Equal(message[:len(term)], term) && wordBoundary(message, len(term))
+	case suffix:
+		at := len(message) - len(term)
+		return at >= 0 && slices.Equal(message[at:], term) && wordBoundary(message, at)
+	}
+	return indexWord(message, term) >= 0
+}
+
+// isWordRune reports whether r can be part of a word.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordBoundary reports whether runes has a word boundary before index i.
+func wordBoundary(runes []rune, i int) bool {
+	return i == 0 || i == len(runes) || !isWordRune(runes[i-1]) || !isWordRune(runes[i])
+}
+
+// indexWord is indexRunes for occurrences of term that are whole words.
+func indexWord(runes, term []rune) int {
+	for from := 0; from <= len(runes)-len(term); {
+		j := indexRunes(runes[from:], term)
+		if j < 0 {
+			return -1
+		}
+		at := from + j
+		if wordBoundary(runes, at) && wordBoundary(runes, at+len(term)) {
+			return at
+		}
+		from = at + 1
+	}
+	return -1
+}
+
+// filterLogs keeps logs matching query within the start and end bounds,
+// dropping any stamped on the exclude date. Entries without a timestamp
+// are only filtered by query.
+func filterLogs(logs []Log, query string, wholeWord bool, start, end, exclude string) []Log {
+	startAt, endAt := boundClock(start), boundClock(end)
+	var result []Log
+	for _, log := range logs {
+		if query != "" && !matchQuery(log.message, query, wholeWord) {
+			continue
+		}
+		if log.timestamp == "" {
+			// Date bounds can't rule out an entry with no timestamp
+			result = append(result, log)
+			continue
+		}
+		// Bounds with a time of day compare by time, as timestamp text
+		// varies ("T" separators, fractions) where the clock doesn't
+		if !startAt.IsZero() && !log.at.IsZero() {
+			if wallClock(log.at).Before(startAt) {
+				continue
+			}
+		} else if start != "" && log.timestamp < start {
+			continue
+		}
+		// An end date takes in its whole day, and an end second its
+		// fractions, 12:59:59.5 included
+		if !endAt.IsZero() && !log.at.IsZero() {
+			if wallClock(log.at).Truncate(time.Second).After(endAt) {
+				continue
+			}
+		} else if end != "" && log.timestamp > end && !strings.HasPrefix(log.timestamp, end) {
+			continue
+		}
+		if exclude != "" && strings.HasPrefix(log.timestamp, exclude) {
+			continue
+		}
+		result = append(result, log)
+	}
+	return result
+}
+
+// boundClock parses a normalized bound that has a time of day, returning
+// zero for date-only or unparsable bounds.
+func boundClock(bound string) time.Time {
+	if len(bound) <= len(canonicalDateFormat) {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation(canonicalTimeFormat, bound, time.UTC)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// wallClock is t as written in the log, its zone dropped, so it compares
+// with bounds the way the timestamps read.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func (m *model) clearFocusedFilter() {
+	switch m.focused {
+	case searchBoxFocused:
+		if value := m.searchBox.Value(); value != "" {
+			m.searchBox.Placeholder = value
+		}
+		m.searchBox.SetValue("")
+	case startDateFocused:
+		m.startDate.SetValue("")
+	case endDateFocused:
+		m.endDate.SetValue("")
+	case excludeDateFocused:
+		m.excludeDate.SetValue("")
+	case logFocus:
+		m.summaryPattern = ""
+		m.sourceFilter = ""
+		m.excludeDate.SetValue("")
+	}
+	m.applyFilters()
+}
+
+func (m *model) applyFilters() {
+	if m.debug {
+		defer func(start time.Time) { m.filterTime = time.Since(start) }(time.Now())
+	}
+
+	if state := m.filterState(); state != m.applied {
+		m.filterHistory = append(m.filterHistory, m.applied)
+		if len(m.filterHistory) > maxFilterHistory {
+			m.filterHistory = m.filterHistory[1:]
+		}
+		m.applied = state
+	}
+	if m.regexSearch {
+		m.compileSearch()
+	}
+
+	// Filter every tab so the tab bar can show where matches are
+	all := m.allLogs()
+	m.tabMatches = make([]int, len(m.categories))
+	m.tabTotals = make([]int, len(m.categories))
+	for i, category := range m.categories {
+		var logs []Log
+		for _, log := range all {
+			if category.matches(log) {
+				logs = append(logs, log)
+			}
+		}
+		m.tabTotals[i] = len(logs)
+		if category.mixed() {
+			logs = m.withoutHidden(logs)
+		}
+		filtered := m.filterCategory(logs)
+		m.tabMatches[i] = len(filtered)
+		if i == m.activeTab {
+			m.filteredLogs = filtered
+		}
+	}
+	m.matchDays = nil
+	if m.showMatchDays && m.searchBox.Value() != "" {
+		m.matchDays = topMatchDays(m.filteredLogs, maxMatchDays)
+	}
+}
+
+// dayCount is how many matches fell on one day.
+type dayCount struct {
+	day   string // YYYY-MM-DD
+	count int
+}
+
+// maxMatchDays is how many days the status bar lists while searching.
+const maxMatchDays = 3
+
+// topMatchDays counts logs by the day of their timestamp and returns the n
+// busiest days, earliest first among equals. Entries without a parsed
+// timestamp are left out.
+func topMatchDays(logs []Log, n int) []dayCount {
+	index := make(map[string]int)
+	var days []dayCount
+	for _, log := range logs {
+		if log.at.IsZero() {
+			continue
+		}
+		day := log.at.Format("2006-01-02")
+		i, ok := index[day]
+		if !ok {
+			i = len(days)
+			index[day] = i
+			days = append(days, dayCount{day: day})
+		}
+		days[i].count++
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		if days[i].count != days[j].count {
+			return days[i].count > days[j].count
+		}
+		return days[i].day < days[j].day
+	})
+	return days[:min(n, len(days))]
+}
+
+// matchDaysLabel lists the busiest days for the status bar, such as
+// "matches: 10-05(7) 10-02(3)".
+func (m model) matchDaysLabel() string {
+	parts := make([]string, len(m.matchDays))
+	for i, d := range m.matchDays {
+		parts[i] = fmt.Sprintf("%s(%d)", d.day[len("2006-"):], d.count)
+	}
+	return "matches: " + strings.Join(parts, " ")
+}
+
+// withoutHidden drops logs whose severity was toggled off with the number
+// keys, which only applies to tabs that mix severities.
+func (m *model) withoutHidden(logs []Log) []Log {
+	if m.hidden == [3]bool{} {
+		return logs
+	}
+	var shown []Log
+	for _, log := range logs {
+		if !m.hidden[log.severity] {
+			shown = append(shown, log)
+		}
+	}
+	return shown
+}
+
+// toggleSeverity shows or hides one severity in mixed tabs.
+func (m *model) toggleSeverity(sev int) {
+	if !m.categories[m.activeTab].mixed() {
+		m.status = "Severity toggles apply to tabs showing several levels, like All"
+		return
+	}
+	m.hidden[sev] = !m.hidden[sev]
+	m.status = ""
+	m.applyFilters()
+	m.resizeTable()
+}
+
+// severitySet describes which severities mixed tabs show, or "" when all are.
+func (m model) severitySet() string {
+	if m.hidden == [3]bool{} {
+		return ""
+	}
+	var shown []string
+	for sev, name := range severityNames {
+		if !m.hidden[sev] {
+			shown = append(shown, name)
+		}
+	}
+	if len(shown) == 0 {
+		return "Severities: none"
+	}
+	return "Severities: " + strings.Join(shown, "+")
+}
+
+// searchText is the message text that searches match against: the
+// redacted message when redacting, unless --search-raw is set.
+func (m *model) searchText(log Log) string {
+	if m.searchRaw {
+		return log.message
+	}
+	return m.redact.apply(log.message)
+}
+
+// matchExpression keeps the logs matching the --query-lang expression
+// query. When it doesn't parse, the error is kept for the status bar and
+// logs are left unfiltered.
+func (m *model) matchExpression(logs []Log, query string) []Log {
+	expr, err := parseQueryExpr(query)
+	m.queryErr = err
+	if err != nil {
+		return logs
+	}
+	var matched []Log
+	for _, log := range logs {
+		if expr.match(log, m.searchText(log)) {
+			matched = append(matched, log)
+		}
+	}
+	return matched
+}
+
+// compileSearch checks the search box as a regular expression, keeping it
+// for filtering when it compiles. Like plain search it ignores case and
+// honours whole words.
+func (m *model) compileSearch() {
+	m.regexErr = nil
+	if m.searchBox.Value() == "" {
+		m.searchRegex = nil
+		return
+	}
+	pattern := m.searchBox.Value()
+	if m.wholeWord {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		m.regexErr = err
+		return
+	}
+	m.searchRegex = re
+}
+
+// toggleRegexSearch switches the search box between plain text and regular
+// expressions, refiltering unless the search is being typed.
+func (m *model) toggleRegexSearch() {
+	m.regexSearch = !m.regexSearch
+	m.queryLang = false
+	m.regexErr = nil
+	m.status = "Plain text search"
+	if m.regexSearch {
+		m.compileSearch()
+		m.status = "Regex search"
+	}
+	if m.focused == logFocus {
+		m.applyFilters()
+		m.initLogTable()
+	}
+}
+
+// matchRegex keeps the logs matching the last search regex that compiled,
+// so a half-typed pattern never leaves the view unfiltered.
+func (m *model) matchRegex(logs []Log) []Log {
+	if m.searchRegex == nil {
+		return logs
+	}
+	var matched []Log
+	for _, log := range logs {
+		if m.searchRegex.MatchString(m.searchText(log)) {
+			matched = append(matched, log)
+		}
+	}
+	return matched
+}
+
+// filterCategory applies the search, date, pattern and source filters to
+// one tab's logs.
+func (m *model) filterCategory(logs []Log) []Log {
+	if m.batchOnly {
+		var batch []Log
+		for _, log := range logs {
+			if log.id >= m.batchFrom {
+				batch = append(batch, log)
+			}
+		}
+		logs = batch
+	}
+	if m.afterMark {
+		var after []Log
+		for _, log := range logs {
+			if m.fromMark(log) {
+				after = append(after, log)
+			}
+		}
+		logs = after
+	}
+
+	query := m.searchBox.Value()
+	switch {
+	case m.queryLang && query != "":
+		logs, query = m.matchExpression(logs, query), ""
+	case m.regexSearch && query != "":
+		logs, query = m.matchRegex(logs), ""
+	case m.redact != nil && !m.searchRaw && query != "":
+		// Search what's shown, so masked values can't be probed for
+		var matched []Log
+		for _, log := range logs {
+			if matchQuery(m.searchText(log), query, m.wholeWord) {
+				matched = append(matched, log)
+			}
+		}
+		logs, query = matched, ""
+	}
+	logs = filterLogs(logs, query, m.wholeWord, m.startBound(), m.endBound(), m.excludeBound())
+
+	if m.summaryPattern != "" {
+		var matched []Log
+		for _, log := range logs {
+			if normalizeMessage(log.message) == m.summaryPattern {
+				matched = append(matched, log)
+			}
+		}
+		logs = matched
+	}
+
+	if m.sourceFilter != "" {
+		var matched []Log
+		for _, log := range logs {
+			if log.source == m.sourceFilter {
+				matched = append(matched, log)
+			}
+		}
+		logs = matched
+	}
+	return logs
+}
+
+// restoreCursor returns to where the cursor was when the active tab was last
+// left, clamped to the tab's current rows.
+func (m *model) restoreCursor() {
+	m.setCursor(max(min(m.tabCursors[m.activeTab], len(m.filteredLogs)-1), 0))
+}
+
+// switchTab activates tab, remembering the cursor of the tab being left. In
+// per-tab filter mode it also stashes the current tab's inputs and restores
+// the ones last used on the new tab.
+func (m *model) switchTab(tab int) {
+	if !m.showSummary {
+		m.tabCursors[m.activeTab] = m.logTable.Cursor()
+	}
+	if m.perTabFilters && tab != m.activeTab {
+		m.tabFilters[m.activeTab] = tabFilters{
+			query:   m.searchBox.Value(),
+			start:   m.startDate.Value(),
+			end:     m.endDate.Value(),
+			exclude: m.excludeDate.Value(),
+		}
+		f := m.tabFilters[tab]
+		m.searchBox.SetValue(f.query)
+		m.startDate.SetValue(f.start)
+		m.endDate.SetValue(f.end)
+		m.excludeDate.SetValue(f.exclude)
+	}
+	m.activeTab = tab
+}
+
+func (m *model) filterState() filterState {
+	return filterState{
+		tab:            m.activeTab,
+		query:          m.searchBox.Value(),
+		start:          m.startDate.Value(),
+		end:            m.endDate.Value(),
+		exclude:        m.excludeDate.Value(),
+		summaryPattern: m.summaryPattern,
+		sourceFilter:   m.sourceFilter,
+		hidden:         m.hidden,
+	}
+}
+
+// sinceLastError sets the start bound to the most recent error so only what
+// happened from then on is shown.
+func (m *model) sinceLastError() {
+	// Prefer the latest parsed time; text only orders errors that have none
+	var last *Log
+	for i := range m.errors {
+		log := &m.errors[i]
+		switch {
+		case last == nil:
+			last = log
+		case !log.at.IsZero() && !last.at.IsZero():
+			if log.at.After(last.at) {
+				last = log
+			}
+		case !log.at.IsZero():
+			last = log
+		case last.at.IsZero() && last.before(*log):
+			last = log
+		}
+	}
+	if last == nil {
+		m.status = "No errors loaded"
+		return
+	}
+
+	bound := last.timestamp
+	if !last.at.IsZero() {
+		bound = last.at.Format(canonicalTimeFormat)
+		if len(last.timestamp) == len(canonicalDateFormat) {
+			bound = last.at.Format(m.dateFormat)
+		}
+	}
+	m.startDate.SetValue(bound)
+	m.showSummary = false
+	m.applyFilters()
+	m.initLogTable()
+	m.status = "Showing entries since last error at " + last.timestamp
+}
+
+// zoom narrows the date range to the minute or hour around the selected
+// entry, or when already zoomed restores the range from before.
+func (m *model) zoom(unit time.Duration) {
+	if m.zoomed {
+		m.startDate.SetValue(m.zoomFrom[0])
+		m.endDate.SetValue(m.zoomFrom[1])
+		m.zoomed = false
+		m.status = "Zoomed back out"
+		m.applyFilters()
+		m.initLogTable()
+		return
+	}
+
+	i := m.logTable.Cursor()
+	if i < 0 || i >= len(m.filteredLogs) {
+		return
+	}
+	log := m.filteredLogs[i]
+	if log.at.IsZero() {
+		m.status = "The selected entry has no time to zoom to"
+		return
+	}
+	minute := 0
+	if unit == time.Minute {
+		minute = log.at.Minute()
+	}
+	at := log.at
+	from := time.Date(at.Year(), at.Month(), at.Day(), at.Hour(), minute, 0, 0, at.Location())
+	to := from.Add(unit - time.Second)
+
+	m.zoomFrom = [2]string{m.startDate.Value(), m.endDate.Value()}
+	m.zoomed = true
+	m.startDate.SetValue(from.Format(canonicalTimeFormat))
+	m.endDate.SetValue(to.Format(canonicalTimeFormat))
+	m.status = fmt.Sprintf("Zoomed to %s–%s (press again to zoom out)", from.Format("15:04:05"), to.Format("15:04:05"))
+	m.applyFilters()
+	m.initLogTable()
+	// Stay on the entry zoomed in on
+	for j, shown := range m.filteredLogs {
+		if shown.id == log.id {
+			m.setCursor(j)
+			break
+		}
+	}
+}
+
+// adjustRange widens (or narrows) the date range by a day at each end. Empty
+// bounds are seeded from the earliest and latest loaded entries.
+func (m *model) adjustRange(widen bool) {
+	start, ok := m.parseBound(m.startDate.Value())
+	if !ok {
+		m.status = "Start date is invalid"
+		return
+	}
+	end, ok := m.parseBound(m.endDate.Value())
+	if !ok {
+		m.status = "End date is invalid"
+		return
+	}
+	if start.IsZero() || end.IsZero() {
+		first, last := m.dataRange()
+		if first.IsZero() {
+			m.status = "No dated entries to seed the range from"
+			return
+		}
+		if start.IsZero() {
+			start = first
+		}
+		if end.IsZero() {
+			end = last
+		}
+	}
+
+	day := 24 * time.Hour
+	if widen {
+		start, end = start.Add(-day), end.Add(day)
+	} else if end.Sub(start) >= 2*day {
+		start, end = start.Add(day), end.Add(-day)
+	}
+	m.startDate.SetValue(start.Format(m.dateFormat))
+	m.endDate.SetValue(end.Format(m.dateFormat))
+	m.showSummary = false
+	m.applyFilters()
+	m.initLogTable()
+	m.status = "Range: " + m.startDate.Value() + " – " + m.endDate.Value()
+}
+
+// parseBound parses a date input, returning the zero time if it's empty.
+func (m *model) parseBound(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.ParseInLocation(m.dateFormat, value, time.Local); err == nil {
+		return t, true
+	}
+	t := parseTimestamp(value)
+	return t, !t.IsZero()
+}
+
+// dataRange returns the days of the earliest and latest dated entries.
+func (m *model) dataRange() (first, last time.Time) {
+	for _, logs := range [][]Log{m.errors, m.warnings, m.info} {
+		for _, log := range logs {
+			if log.at.IsZero() {
+				continue
+			}
+			if first.IsZero() || log.at.Before(first) {
+				first = log.at
+			}
+			if last.IsZero() || log.at.After(last) {
+				last = log.at
+			}
+		}
+	}
+	y, mo, d := first.Date()
+	first = time.Date(y, mo, d, 0, 0, 0, 0, first.Location())
+	y, mo, d = last.Date()
+	last = time.Date(y, mo, d, 0, 0, 0, 0, last.Location())
+	return first, last
+}
+
+// undoFilter restores the filters in effect before the last applied change.
+func (m *model) undoFilter() {
+	if len(m.filterHistory) == 0 {
+		m.status = "Nothing to undo"
+		return
+	}
+	state := m.filterHistory[len(m.filterHistory)-1]
+	m.filterHistory = m.filterHistory[:len(m.filterHistory)-1]
+
+	m.activeTab = state.tab
+	m.searchBox.SetValue(state.query)
+	m.startDate.SetValue(state.start)
+	m.endDate.SetValue(state.end)
+	m.excludeDate.SetValue(state.exclude)
+	m.summaryPattern = state.summaryPattern
+	m.sourceFilter = state.sourceFilter
+	m.hidden = state.hidden
+	m.applied = state
+	m.showSummary = false
+	m.applyFilters()
+	m.initLogTable()
+}
+
+// filterBySelected narrows the view to rows like the selected one, either by
+// searching for its message or by matching its source exactly.
+func (m *model) filterBySelected(bySource bool) {
+	i := m.logTable.Cursor()
+	if i < 0 || i >= len(m.filteredLogs) {
+		return
+	}
+	log := m.filteredLogs[i]
+	if bySource {
+		if log.source == "" {
+			return
+		}
+		m.sourceFilter = log.source
+	} else if m.regexSearch {
+		m.searchBox.SetValue(regexp.QuoteMeta(log.message))
+	} else {
+		m.searchBox.SetValue(log.message)
+	}
+	m.applyFilters()
+	m.initLogTable()
+}
+
+func (m *model) startBound() string {
+	if m.noDates {
+		return ""
+	}
+	bound, _ := normalizeDate(m.startDate.Value(), m.dateFormat)
+	return bound
+}
+
+func (m *model) endBound() string {
+	if m.noDates {
+		return ""
+	}
+	bound, _ := normalizeDate(m.endDate.Value(), m.dateFormat)
+	return bound
+}
+
+// noDatesNote replaces the date inputs when no entry has a timestamp.
+const noDatesNote = "Date filtering disabled: no timestamps found"
+
+// datesDisabled reports, and explains in the status bar, that the loaded
+// logs have no timestamps to filter by.
+func (m *model) datesDisabled() bool {
+	if m.noDates {
+		m.status = noDatesNote
+	}
+	return m.noDates
+}
+
+// hasTimestamps reports whether any loaded entry has a parsed time.
+func (m *model) hasTimestamps() bool {
+	for _, logs := range [][]Log{m.errors, m.warnings, m.info} {
+		for _, log := range logs {
+			if !log.at.IsZero() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excludeBound is the canonical date whose entries are hidden, if any.
+func (m *model) excludeBound() string {
+	if m.noDates {
+		return ""
+	}
+	bound, _ := normalizeDate(m.excludeDate.Value(), m.dateFormat)
+	if len(bound) > len(canonicalDateFormat) {
+		bound = bound[:len(canonicalDateFormat)]
+	}
+	return bound
+}
+
+// dateHint flags a date input that doesn't match the configured format.
+func (m model) dateHint(value string) string {
+	if _, ok := normalizeDate(value, m.dateFormat); !ok {
+		return " (invalid)"
+	}
+	return ""
+}
+
+// normalizeDate parses value using layout and returns it in the canonical
+// sortable form. Empty or unparsable values yield an empty bound.
+func normalizeDate(value, layout string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", true
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		// Also accept full timestamps, as set by the "since last error" filter
+		if t = parseTimestamp(value); t.IsZero() {
+			return "", false
+		}
+		if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+			return t.Format(canonicalTimeFormat), true
+		}
+	}
+	return t.Format(canonicalDateFormat), true
+}
+
+// validateDateFormat checks that layout is a Go time layout that round-trips
+// a full calendar date.
+func validateDateFormat(layout string) error {
+	ref := time.Date(2024, time.October, 5, 0, 0, 0, 0, time.UTC)
+	formatted := ref.Format(layout)
+	if formatted == layout {
+		return fmt.Errorf("date format %q contains no layout elements", layout)
+	}
+	t, err := time.Parse(layout, formatted)
+	if err != nil {
+		return fmt.Errorf("date format %q: %w", layout, err)
+	}
+	if t.Year() != ref.Year() || t.Month() != ref.Month() || t.Day() != ref.Day() {
+		return fmt.Errorf("date format %q must include year, month and day", layout)
+	}
+	return nil
+}
+
+// normalizeMessage strips IDs and digits so that otherwise identical
+// messages group together.
+func normalizeMessage(msg string) string {
+	msg = idPattern.ReplaceAllString(msg, "<id>")
+	msg = digitPattern.ReplaceAllString(msg, "#")
+	return strings.TrimSpace(msg)
+}
+
+// promoteRepeatedWarnings raises warnings to errors when more than after of
+// them share a normalized message within window (zero means any span), so
+// chronic issues surface on the Errors tab. It returns how many it promoted.
+func (m *model) promoteRepeatedWarnings(after int, window time.Duration) int {
+	groups := make(map[string][]int)
+	for i, log := range m.warnings {
+		pattern := normalizeMessage(log.message)
+		groups[pattern] = append(groups[pattern], i)
+	}
+
+	promote := make(map[int]bool)
+	for _, group := range groups {
+		sort.SliceStable(group, func(a, b int) bool {
+			return m.warnings[group[a]].before(m.warnings[group[b]])
+		})
+		start := 0
+		for end := range group {
+			if window > 0 {
+				for m.warnings[group[end]].at.Sub(m.warnings[group[start]].at) > window {
+					start++
+				}
+			}
+			if end-start+1 > after {
+				for _, i := range group[start : end+1] {
+					promote[i] = true
+				}
+			}
+		}
+	}
+
+	var kept []Log
+	for i, log := range m.warnings {
+		if promote[i] {
+			log.severity = Errors
+			log.promoted = true
+			m.errors = append(m.errors, log)
+		} else {
+			kept = append(kept, log)
+		}
+	}
+	m.warnings = kept
+	return len(promote)
+}
+
+// summarizeErrors groups logs by normalized message, sorted by count.
+func summarizeErrors(logs []Log) []errorSummary {
+	index := make(map[string]int)
+	var summaries []errorSummary
+	for _, log := range logs {
+		pattern := normalizeMessage(log.message)
+		i, ok := index[pattern]
+		if !ok {
+			index[pattern] = len(summaries)
+			summaries = append(summaries, errorSummary{
+				pattern:  pattern,
+				severity: log.severity,
+				first:    log.timestamp,
+				last:     log.timestamp,
+			})
+			i = len(summaries) - 1
+		}
+		s := &summaries[i]
+		s.count++
+		if log.timestamp != "" && (s.first == "" || log.timestamp < s.first) {
+			s.first = log.timestamp
+		}
+		if log.timestamp > s.last {
+			s.last = log.timestamp
+		}
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].count > summaries[j].count
+	})
+	return summaries
+}
+
+// addLogs assigns IDs to new entries and sorts them into the per-severity
+// slices.
+func (m *model) addLogs(logs []Log) {
+	for _, log := range logs {
+		m.nextID++
+		log.id = m.nextID
+		log.at = parseTimestamp(log.timestamp)
+		if log.ingested != "" {
+			log.ingestedAt = parseTimestamp(log.ingested)
+			m.hasIngest = true
+		}
+		if log.unknown {
+			m.unknownLevels++
+		}
+		if m.byIngest {
+			log = log.swapTimes()
+		}
+		if m.trimSpace || m.collapseSpaces {
+			if tidy := tidyMessage(log.message, m.collapseSpaces); tidy != log.message {
+				log.raw, log.message = log.message, tidy
+			}
+		}
+		if short := truncateMessage(log.message, m.maxMsgLen); short != log.message {
+			if m.keepFull {
+				log.full = log.message
+			} else {
+				log.raw = "" // Don't keep what --max-msg-len dropped
+			}
+			log.message = short
+		}
+		switch log.severity {
+		case Errors:
+			m.errors = append(m.errors, log)
+		case Warnings:
+			m.warnings = append(m.warnings, log)
+		default:
+			m.info = append(m.info, log)
+		}
+	}
+}
+
+// gapBefore returns the time since the previous filtered row when it exceeds
+// the configured gap threshold, or zero otherwise.
+func (m *model) gapBefore(i int) time.Duration {
+	if m.gap <= 0 || i == 0 {
+		return 0
+	}
+	prev, cur := m.filteredLogs[i-1].at, m.filteredLogs[i].at
+	if prev.IsZero() || cur.IsZero() {
+		return 0
+	}
+	if gap := cur.Sub(prev); gap > m.gap {
+		return gap
+	}
+	return 0
+}
+
+// formatGap renders a duration compactly, e.g. "12m" or "1h5m".
+func formatGap(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatDelta renders the time since the previous row, e.g. "+1.2s" or
+// "+3m20s". Entries stamped earlier than their predecessor go negative.
+func formatDelta(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Minute {
+		return sign + strconv.FormatFloat(d.Seconds(), 'f', 1, 64) + "s"
+	}
+	return sign + formatGap(d)
+}
+
+// spaceRun matches runs of spaces and tabs for --collapse-spaces.
+var spaceRun = regexp.MustCompile(`[ \t]{2,}|\t`)
+
+// tidyMessage trims whitespace around msg and, with collapse, squeezes runs
+// of spaces and tabs inside it to a single space. Line breaks are kept.
+func tidyMessage(msg string, collapse bool) string {
+	msg = strings.TrimSpace(msg)
+	if collapse {
+		msg = spaceRun.ReplaceAllString(msg, " ")
+	}
+	return msg
+}
+
+// truncateMessage shortens msg to at most n runes, marking the cut with an
+// ellipsis. A non-positive n disables truncation.
+func truncateMessage(msg string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(msg) <= n {
+		return msg
+	}
+	runes := []rune(msg)
+	return string(runes[:max(n-1, 0)]) + "…"
+}
+
+// toggleSelected adds or removes the row under the cursor from the selection.
+func (m *model) toggleSelected() {
+	i := m.logTable.Cursor()
+	if i < 0 || i >= len(m.filteredLogs) {
+		return
+	}
+	id := m.filteredLogs[i].id
+	if m.selected[id] {
+		delete(m.selected, id)
+	} else {
+		m.selected[id] = true
+	}
+	m.initLogTable()
+	m.setCursor(i)
+}
+
+// selectAllVisible selects every row that passes the current filters.
+func (m *model) selectAllVisible() {
+	for _, log := range m.filteredLogs {
+		m.selected[log.id] = true
+	}
+	cursor := m.logTable.Cursor()
+	m.initLogTable()
+	m.setCursor(cursor)
+}
+
+// invertSelection flips the selection of every visible row, leaving rows
+// hidden by the filters as they were.
+func (m *model) invertSelection() {
+	for _, log := range m.filteredLogs {
+		if m.selected[log.id] {
+			delete(m.selected, log.id)
+		} else {
+			m.selected[log.id] = true
+		}
+	}
+	m.status = fmt.Sprintf("%d rows selected", len(m.selected))
+	cursor := m.logTable.Cursor()
+	m.initLogTable()
+	m.setCursor(cursor)
+}
+
+// selectedLogs returns the selected entries in chronological order.
+func (m *model) selectedLogs() []Log {
+	var logs []Log
+	for _, log := range m.allLogs() {
+		if m.selected[log.id] {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// promptExport asks where to export the selection, defaulting to --export.
+func (m *model) promptExport() {
+	if len(m.selectedLogs()) == 0 {
+		m.status = "Nothing selected"
+		return
+	}
+	m.focused = exportFocused
+	m.exportBox.SetValue(m.exportPath)
+	m.exportBox.CursorEnd()
+	m.exportBox.Focus()
+	m.status = ""
+	m.resizeTable()
+}
+
+// submitExport writes the export, first asking before replacing a file.
+func (m *model) submitExport() {
+	path := strings.TrimSpace(m.exportBox.Value())
+	if path == "" {
+		m.status = "Enter a file name to export to"
+		return
+	}
+	if _, err := os.Stat(path); err == nil {
+		m.pendingPath = path
+		m.focused = overwriteFocused
+		m.status = path + " exists. Overwrite? (y/n)"
+		return
+	}
+	m.finishExport(path)
+}
+
+// confirmOverwrite completes or abandons an export to an existing file.
+func (m *model) confirmOverwrite(ok bool) {
+	if ok {
+		m.finishExport(m.pendingPath)
+	} else {
+		m.endExport()
+		m.status = "Export cancelled"
+	}
+	m.pendingPath = ""
+}
+
+func (m *model) finishExport(path string) {
+	m.endExport()
+	m.exportSelection(path)
+}
+
+func (m *model) endExport() {
+	m.focused = logFocus
+	m.exportBox.Blur()
+	m.resizeTable()
+}
+
+// resizeTable rebuilds the table for a layout change, keeping the cursor.
+func (m *model) resizeTable() {
+	cursor := m.logTable.Cursor()
+	m.initLogTable()
+	m.setCursor(cursor)
+}
+
+// exportFilters describes the current filters for an export header, or is
+// nil with --plain-export.
+func (m *model) exportFilters() *exportFilters {
+	if m.plainExport {
+		return nil
+	}
+	state := m.filterState()
+	return &exportFilters{
+		Tab:     m.categories[state.tab].name,
+		Query:   state.query,
+		Start:   state.start,
+		End:     state.end,
+		Exclude: state.exclude,
+		Pattern: state.summaryPattern,
+		Source:  state.sourceFilter,
+	}
+}
+
+// exportHTML writes the filtered rows to a timestamped HTML report in the
+// working directory.
+func (m *model) exportHTML() {
+	path := "report-" + time.Now().Format("20060102-150405") + ".html"
+	if err := exportLogs(path, m.redact.logs(m.filteredLogs), m.exportFilters()); err != nil {
+		m.status = "Export failed: " + err.Error()
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	m.status = fmt.Sprintf("Exported %d rows to %s", len(m.filteredLogs), path)
+}
+
+func (m *model) exportSelection(path string) {
+	logs := m.selectedLogs()
+	if len(logs) == 0 {
+		m.status = "Nothing selected"
+		return
+	}
+	if err := exportLogs(path, m.redact.logs(logs), m.exportFilters()); err != nil {
+		m.status = "Export failed: " + err.Error()
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	m.status = fmt.Sprintf("Exported %d rows to %s", len(logs), path)
+}
+
+func (m *model) copySelection() {
+	logs := m.selectedLogs()
+	if len(logs) == 0 {
+		m.status = "Nothing selected"
+		return
+	}
+	if err := copyLogs(m.redact.logs(logs)); err != nil {
+		m.status = "Copy failed: " + err.Error()
+		return
+	}
+	m.status = fmt.Sprintf("Copied %d rows to clipboard", len(logs))
+}
+
+// copyTimestamp copies the timestamp of the row under the cursor as it
+// appeared in the log, whatever the display format.
+func (m *model) copyTimestamp() {
+	i := m.logTable.Cursor()
+	if i < 0 || i >= len(m.filteredLogs) || m.filteredLogs[i].timestamp == "" {
+		m.status = "No timestamp to copy"
+		return
+	}
+	ts := m.filteredLogs[i].timestamp
+	if err := clipboard.WriteAll(ts); err != nil {
+		m.status = "Clipboard unavailable: " + err.Error()
+		return
+	}
+	m.status = "Copied " + ts
+}
+
+// filterCommand builds a command line that reopens the current view.
+func (m *model) filterCommand() string {
+	state := m.filterState()
+	args := append([]string{filepath.Base(os.Args[0])}, m.launchArgs...)
+	args = append(args, "--tab", m.categories[state.tab].name)
+	for _, arg := range [][2]string{{"search", state.query}, {"from", state.start}, {"to", state.end}, {"exclude-date", state.exclude}} {
+		if arg[1] != "" {
+			args = append(args, "--"+arg[0], arg[1])
+		}
+	}
+	if m.wholeWord && state.query != "" {
+		args = append(args, "--whole-word")
+	}
+	if m.queryLang && state.query != "" {
+		args = append(args, "--query-lang")
+	}
+	if m.regexSearch && state.query != "" {
+		args = append(args, "--regex")
+	}
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=@,+-]+$`)
+
+// shellQuote single-quotes s for a POSIX shell unless it's already safe.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (m *model) copyCommand() {
+	command := m.filterCommand()
+	if err := clipboard.WriteAll(command); err != nil {
+		m.status = "Copy failed: " + err.Error()
+		return
+	}
+	m.status = "Copied " + command
+}
+
+// logKey identifies an entry by content, so reloads can tell which entries
+// are unchanged.
+func logKey(log Log) string {
+	return strings.Join([]string{log.timestamp, log.level, log.source, log.message}, "\x00")
+}
+
+// describeOrigin names what was loaded for --show-origin, with paths under
+// the home directory abbreviated to ~.
+func describeOrigin(file, dir string, stdin bool) string {
+	var parts []string
+	for _, path := range []string{file, dir} {
+		switch {
+		case path == "":
+			continue
+		case isURL(path):
+			parts = append(parts, path)
+		default:
+			parts = append(parts, abbreviateHome(path))
+		}
+	}
+	if stdin {
+		parts = append(parts, "<stdin>")
+	}
+	return strings.Join(parts, " + ")
+}
+
+// abbreviateHome makes path absolute and replaces the home directory with ~.
+func abbreviateHome(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		if rel == "." {
+			return "~"
+		}
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// reloadFile re-reads filePath, keeping entries that are still present (and
+// with them their IDs and selection) while dropping ones that disappeared,
+// so files that are rewritten rather than appended to stay accurate.
+func (m *model) reloadFile() (added, removed int, err error) {
+	logs, err := loadFile(m.filePath, m.tailBytes)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Identical lines are told apart by count: the nth copy of a line in
+	// the file is the nth entry kept for it
+	counts := make(map[string]int, len(logs))
+	var fresh []Log
+	for _, log := range logs {
+		key := logKey(log)
+		counts[key]++
+		if counts[key] > len(m.fileKeys[key]) {
+			fresh = append(fresh, log)
+		}
+	}
+
+	stale := make(map[int]bool)
+	for key, ids := range m.fileKeys {
+		n := counts[key]
+		if n >= len(ids) {
+			continue
+		}
+		for _, id := range ids[n:] {
+			stale[id] = true
+		}
+		if n == 0 {
+			delete(m.fileKeys, key)
+		} else {
+			m.fileKeys[key] = ids[:n]
+		}
+	}
+	if len(stale) > 0 {
+		m.removeLogs(stale)
+	}
+
+	m.recordFileKeys(fresh)
+	m.addLogs(fresh)
+	m.loadedAt = time.Now()
+	return len(fresh), len(stale), nil
+}
+
+// recordFileKeys notes the entries from filePath among logs, which are about
+// to be added, under the IDs addLogs will give them. Keys are taken before
+// addLogs trims or truncates messages, as loadFile returns them.
+func (m *model) recordFileKeys(logs []Log) {
+	if m.fileKeys == nil {
+		m.fileKeys = make(map[string][]int)
+	}
+	source := filepath.Base(m.filePath)
+	for i, log := range logs {
+		if log.source == source {
+			key := logKey(log)
+			m.fileKeys[key] = append(m.fileKeys[key], m.nextID+1+i)
+		}
+	}
+}
+
+// removeLogs drops the entries with the given IDs.
+func (m *model) removeLogs(ids map[int]bool) {
+	keep := func(logs []Log) []Log {
+		kept := logs[:0]
+		for _, log := range logs {
+			if !ids[log.id] {
+				kept = append(kept, log)
+			} else if log.unknown {
+				m.unknownLevels--
+			}
+		}
+		return kept
+	}
+	m.errors, m.warnings, m.info = keep(m.errors), keep(m.warnings), keep(m.info)
+	for id := range ids {
+		delete(m.selected, id)
+	}
+}
+
+// refreshMsg triggers an auto-refresh reload of filePath.
+type refreshMsg struct {
+	seq int
+}
+
+func (m *model) scheduleRefresh() tea.Cmd {
+	seq := m.refreshSeq
+	return tea.Tick(m.refreshInterval, func(time.Time) tea.Msg {
+		return refreshMsg{seq: seq}
+	})
+}
+
+// findNext moves the cursor to the next (or previous) row matching the find
+// query, or the search term if nothing was entered with "?", wrapping at
+// either end. Unlike the search filter it leaves other rows in view.
+func (m *model) findNext(forward bool) {
+	query := m.findQuery
+	if query == "" {
+		query = m.searchBox.Value()
+	}
+	if query == "" {
+		m.status = "Nothing to find"
+		return
+	}
+
+	var matches []int
+	for i, log := range m.filteredLogs {
+		if matchQuery(m.searchText(log), query, m.wholeWord) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		m.status = "No matches for " + query
+		return
+	}
+
+	cursor := m.logTable.Cursor()
+	next := 0
+	if forward {
+		next = sort.SearchInts(matches, cursor+1)
+		if next == len(matches) {
+			next = 0
+		}
+	} else {
+		next = sort.SearchInts(matches, cursor) - 1
+		if next < 0 {
+			next = len(matches) - 1
+		}
+	}
+	m.setCursor(matches[next])
+	m.status = fmt.Sprintf("match %d of %d", next+1, len(matches))
+}
+
+// refreshLogs re-applies filters after new logs arrive, keeping the cursor.
+func (m *model) refreshLogs() {
+	if m.showSummary {
+		return
+	}
+	cursor := m.logTable.Cursor()
+	following := m.following()
+	m.checkClock()
+	m.applyFilters()
+	m.initLogTable()
+	if following {
+		cursor = len(m.filteredLogs) - 1
+	}
+	m.setCursor(cursor)
+}
+
+// Where the cursor goes when new or reloaded entries arrive.
+const (
+	cursorKeep   = iota // stay put, following the bottom row like tail -f
+	cursorNewest        // jump to the newest matching entry
+	cursorFirst         // jump to the first matching entry
+)
+
+// cursorModes names the cursor modes for --on-update and the status bar.
+var cursorModes = []string{"keep", "newest", "first"}
+
+// afterUpdate refreshes the table for new or reloaded entries, keeping the
+// filters, then moves the cursor as the update mode asks.
+func (m *model) afterUpdate() {
+	m.refreshLogs()
+	if m.showSummary {
+		return
+	}
+	switch m.onUpdate {
+	case cursorNewest:
+		m.setCursor(len(m.filteredLogs) - 1)
+	case cursorFirst:
+		m.setCursor(0)
+	}
+}
+
+// cycleOnUpdate switches to the next cursor mode.
+func (m *model) cycleOnUpdate() {
+	m.onUpdate = (m.onUpdate + 1) % len(cursorModes)
+	m.status = "On update: " + cursorModes[m.onUpdate]
+}
+
+// minTimestampWidth is the narrowest the timestamp column can be dragged.
+const minTimestampWidth = 8
+
+// dragColumn resizes the timestamp column when its right edge is dragged.
+// The edge is the column's trailing padding cell.
+func (m *model) dragColumn(msg tea.MouseMsg) {
+	if m.timestampX < 0 {
+		return
+	}
+	var width int
+	for _, c := range m.logTable.Columns() {
+		if c.Title == columnTitles["timestamp"] {
+			width = c.Width
+		}
+	}
+	edge := m.timestampX + width + 1
+
+	switch {
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		m.dragging = msg.X >= edge-1 && msg.X <= edge+1
+	case msg.Action == tea.MouseActionMotion && m.dragging:
+		// Leave the message column its minimum width
+		limit := max(width+m.messageWidth-10, minTimestampWidth)
+		m.timestampWidth = min(max(msg.X-m.timestampX-1, minTimestampWidth), limit)
+		m.resizeTable()
+	case msg.Action == tea.MouseActionRelease:
+		m.dragging = false
+	}
+}
+
+// checkClock flags entries whose parsed time is earlier than the previous
+// entry from the same source, in the order they were read, which points at
+// clock skew or interleaved writers. Duplicate timestamps are only counted.
+func (m *model) checkClock() {
+	m.outOfOrder = nil
+	if !m.clockCheck {
+		return
+	}
+	logs := m.allLogs()
+	sort.Slice(logs, func(i, j int) bool { return logs[i].id < logs[j].id })
+
+	m.outOfOrder = make(map[int]bool)
+	duplicates := 0
+	last := make(map[string]time.Time)
+	for _, log := range logs {
+		if log.at.IsZero() {
+			continue
+		}
+		prev, ok := last[log.source]
+		switch {
+		case ok && log.at.Before(prev):
+			m.outOfOrder[log.id] = true
+			continue // Keep comparing against the latest time seen
+		case ok && log.at.Equal(prev):
+			duplicates++
+		}
+		last[log.source] = log.at
+	}
+	m.status = fmt.Sprintf("Clock check: %d out of order (↶), %d duplicate timestamps", len(m.outOfOrder), duplicates)
+}
+
+// finishLoad runs the steps that need the whole initial load and records
+// how long it took.
+func (m *model) finishLoad() {
+	if m.promoteAfter > 0 {
+		if n := m.promoteRepeatedWarnings(m.promoteAfter, m.promoteWindow); n > 0 {
+			m.status = fmt.Sprintf("Promoted %d repeated warnings to errors (⇑)", n)
+		}
+	}
+	m.loadTime = time.Since(m.loadStart)
+	m.loadedAt = time.Now()
+	m.loadRows = len(m.errors) + len(m.warnings) + len(m.info)
+	// Only judge what was loaded; followed sources may start out empty
+	m.noDates = m.loadRows > 0 && !m.hasTimestamps()
+}
+
+// finishProgressiveLoad ends the streamed load of filePath, whose entries
+// were keyed as they arrived so auto-refresh can tell them apart from new
+// ones.
+func (m *model) finishProgressiveLoad() {
+	m.loading = false
+	m.finishLoad()
+	if m.loadOnly {
+		// Nothing else feeds the channel; stop waiting on it
+		m.live = nil
+	}
+}
+
+// toggleIngestTime switches sorting and date filtering between event and
+// ingest times.
+func (m *model) toggleIngestTime() {
+	if !m.hasIngest {
+		m.status = "No entries have an ingest time"
+		return
+	}
+	m.byIngest = !m.byIngest
+	for _, logs := range [][]Log{m.errors, m.warnings, m.info} {
+		for i := range logs {
+			logs[i] = logs[i].swapTimes()
+		}
+	}
+	m.status = "Sorting and filtering by event time"
+	if m.byIngest {
+		m.status = "Sorting and filtering by ingest time"
+	}
+	m.applyFilters()
+	m.resizeTable()
+}
+
+// toggleSeverityTies switches between reading order and errors first for
+// entries that share a timestamp.
+func (m *model) toggleSeverityTies() {
+	m.severityTies = !m.severityTies
+	m.status = "Same-time entries in reading order"
+	if m.severityTies {
+		m.status = "Same-time entries sorted errors first"
+	}
+	m.applyFilters()
+	m.resizeTable()
+}
+
+// addBatch adds entries from a followed source, recording them as the
+// newest batch for the batch-only view.
+func (m *model) addBatch(logs []Log) {
+	m.batchFrom, m.batchAt = m.nextID+1, time.Now()
+	m.addLogs(logs)
+	m.recordArrival(logs)
+}
+
+// rateWindow is how far back the follow-mode rate looks.
+const rateWindow = time.Minute
+
+// arrival counts one followed batch's entries by severity.
+type arrival struct {
+	at     time.Time
+	counts [3]int
+}
+
+// recordArrival notes logs arriving now, dropping arrivals too old to
+// count toward the rate.
+func (m *model) recordArrival(logs []Log) {
+	now := time.Now()
+	a := arrival{at: now}
+	for _, log := range logs {
+		a.counts[log.severity]++
+	}
+	cutoff := now.Add(-rateWindow)
+	kept := m.arrivals[:0]
+	for _, old := range m.arrivals {
+		if old.at.After(cutoff) {
+			kept = append(kept, old)
+		}
+	}
+	m.arrivals = append(kept, a)
+}
+
+// computeRate returns entries per minute by severity over the last window,
+// counting when entries arrived rather than their timestamps, which may lag.
+func (m *model) computeRate(window time.Duration) map[int]float64 {
+	cutoff := time.Now().Add(-window)
+	rates := make(map[int]float64, len(severityNames))
+	for _, a := range m.arrivals {
+		if a.at.After(cutoff) {
+			for sev, n := range a.counts {
+				rates[sev] += float64(n)
+			}
+		}
+	}
+	for sev := range rates {
+		rates[sev] /= window.Minutes()
+	}
+	return rates
+}
+
+// rateLabel shows the follow-mode rate per severity, e.g. "/min ✖ 2 ▲ 0.5 ● 40".
+func (m *model) rateLabel() string {
+	rates := m.computeRate(rateWindow)
+	parts := []string{"/min"}
+	for sev, glyph := range severityGlyphs {
+		parts = append(parts, glyph+" "+strconv.FormatFloat(math.Round(rates[sev]*10)/10, 'f', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+// idleMsg checks whether --idle-timeout has passed without activity.
+type idleMsg time.Time
+
+// idleCheck schedules an idleMsg for when the idle timeout would run out
+// if nothing happens before then.
+func (m *model) idleCheck() tea.Cmd {
+	return tea.Tick(m.idleTimeout-time.Since(m.lastActivity), func(t time.Time) tea.Msg {
+		return idleMsg(t)
+	})
+}
+
+// rateTickMsg redraws the rate so it falls once entries stop arriving.
+type rateTickMsg time.Time
+
+// rateRefresh is how often the rate is redrawn without new entries.
+const rateRefresh = 5 * time.Second
+
+func rateTick() tea.Cmd {
+	return tea.Tick(rateRefresh, func(t time.Time) tea.Msg {
+		return rateTickMsg(t)
+	})
+}
+
+// toggleBatchOnly switches between all entries and just the newest batch.
+func (m *model) toggleBatchOnly() {
+	if m.live == nil || m.loadOnly {
+		m.status = "Batch view needs a followed source (--dir or a URL)"
+		return
+	}
+	m.batchOnly = !m.batchOnly
+	if m.batchOnly && m.batchAt.IsZero() {
+		// Nothing has arrived since loading; wait for the first batch
+		m.batchFrom = m.nextID + 1
+	}
+	m.applyFilters()
+	m.resizeTable()
+}
+
+// setMark bookmarks the selected row, or the current time when no row is
+// shown. Marking the already-marked row clears the mark.
+func (m *model) setMark() {
+	cursor := m.logTable.Cursor()
+	switch {
+	case cursor >= 0 && cursor < len(m.filteredLogs):
+		log := m.filteredLogs[cursor]
+		if m.mark != nil && m.mark.id == log.id {
+			m.mark, m.afterMark = nil, false
+			m.status = "Mark cleared"
+			m.applyFilters()
+			m.resizeTable()
+			return
+		}
+		m.mark = &log
+	default:
+		// Stands where the next entry to arrive will
+		now := time.Now()
+		m.mark = &Log{id: m.nextID + 1, timestamp: now.Format(canonicalTimeFormat), at: now}
+	}
+	m.status = "Marked " + m.markLabel()
+	m.applyFilters()
+	m.resizeTable()
+}
+
+// markLabel names the mark by its timestamp, or its row when it has none.
+func (m *model) markLabel() string {
+	if m.mark.timestamp == "" {
+		return fmt.Sprintf("entry #%d", m.mark.id)
+	}
+	return m.mark.timestamp
+}
+
+// fromMark reports whether log comes at or after the mark, entries stamped
+// the same as the mark going by load order.
+func (m *model) fromMark(log Log) bool {
+	if log.before(*m.mark) {
+		return false
+	}
+	if m.mark.before(log) {
+		return true
+	}
+	return log.id >= m.mark.id
+}
+
+// markBefore reports whether the mark divider belongs above the i-th
+// filtered row: the marked row itself or the first shown after the mark.
+func (m *model) markBefore(i int) bool {
+	if m.mark == nil {
+		return false
+	}
+	log := m.filteredLogs[i]
+	if log.id == m.mark.id {
+		return true
+	}
+	return i > 0 && m.fromMark(log) && !m.fromMark(m.filteredLogs[i-1])
+}
+
+// jumpToMark moves the cursor to the first shown row from the mark on.
+func (m *model) jumpToMark() {
+	if m.mark == nil {
+		m.status = "No mark set (M to set one)"
+		return
+	}
+	for i, log := range m.filteredLogs {
+		if m.fromMark(log) {
+			m.setCursor(i)
+			m.status = "At mark " + m.markLabel()
+			return
+		}
+	}
+	m.status = "Nothing shown after the mark yet"
+}
+
+// toggleAfterMark switches between all entries and those from the mark on.
+func (m *model) toggleAfterMark() {
+	if m.mark == nil {
+		m.status = "No mark set (M to set one)"
+		return
+	}
+	m.afterMark = !m.afterMark
+	m.applyFilters()
+	m.resizeTable()
+}
+
+func (m model) batchHeader() string {
+	if m.batchAt.IsZero() {
+		return "Batch: waiting for new entries (D to show all)"
+	}
+	return fmt.Sprintf("Batch: %d new at %s (D to show all)",
+		m.nextID+1-m.batchFrom, m.batchAt.Format("15:04:05"))
+}
+
+// following reports whether the cursor sits on the last row, in which case
+// new lines keep it pinned to the bottom like tail -f.
+func (m *model) following() bool {
+	return m.logTable.Cursor() >= len(m.filteredLogs)-1
+}
+
+// allLogs merges every severity into a single chronologically sorted slice.
+func (m *model) allLogs() []Log {
+	logs := make([]Log, 0, len(m.errors)+len(m.warnings)+len(m.info))
+	logs = append(logs, m.errors...)
+	logs = append(logs, m.warnings...)
+	logs = append(logs, m.info...)
+	sortLogs(logs, m.severityTies)
+	return logs
+}
+
+// nextBySeverity returns the index of the next (or previous) filtered log
+// with the given severity, starting after from. It returns -1 if none exists.
+func (m *model) nextBySeverity(from, sev int, forward bool) int {
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for i := from + step; i >= 0 && i < len(m.filteredLogs); i += step {
+		if m.filteredLogs[i].severity == sev {
+			return i
+		}
+	}
+	return -1
+}
+
+// jumpToError moves the selection to the next or previous error. Outside the
+// All tab it switches to the All tab first, keeping the current row in place.
+func (m *model) jumpToError(forward bool) {
+	from := m.logTable.Cursor()
+	allTab := m.findTab(func(c category) bool { return c.levels == nil })
+	if allTab >= 0 && m.activeTab != allTab {
+		var current *Log
+		if from >= 0 && from < len(m.filteredLogs) {
+			current = &m.filteredLogs[from]
+		}
+		m.switchTab(allTab)
+		m.applyFilters()
+		m.initLogTable()
+		from = -1
+		if !forward {
+			from = len(m.filteredLogs)
+		}
+		if current != nil {
+			for i, log := range m.filteredLogs {
+				if log.id == current.id {
+					from = i
+					break
+				}
+			}
+		}
+		m.setCursor(max(from, 0))
+	}
+	if next := m.nextBySeverity(from, Errors, forward); next >= 0 {
+		m.setCursor(next)
+	}
+}
+
+// sampleLogs are shown when no log file or directory is given.
+var sampleLogs = []Log{
+	{timestamp: "2024-10-01", message: "authentication failure", severity: Errors},
+	{timestamp: "2024-10-05", message: "out of memory", severity: Errors},
+	{timestamp: "2024-10-02", message: "disk usage high", severity: Warnings},
+	{timestamp: "2024-10-06", message: "CPU usage high", severity: Warnings},
+	{timestamp: "2024-10-01", message: "service started", severity: Information},
+	{timestamp: "2024-10-04", message: "configuration loaded", severity: Information},
+}
+
+func main() {
+	dateFormat := flag.String("date-format", canonicalDateFormat, "Go time layout used for the date filter inputs")
+	file := flag.String("file", "", "log file or http(s) URL to load (plain text or Windows Event Log CSV/XML export); a named pipe is followed live")
+	dir := flag.String("dir", "", "directory of *.log files to load and follow")
+	tailBytes := flag.Int64("tail-bytes", 0, "load only the complete lines in the last N bytes of --file or each --dir file (0 loads everything)")
+	progressive := flag.Bool("progressive", false, "show a plain-text --file while it's still being read, rather than after (no Windows Event Log detection)")
+	stdin := flag.Bool("stdin", false, "after loading --file or --dir, follow lines piped on stdin; all entries are shown in timestamp order")
+	grep := flag.String("grep", "", "only load plain-text lines matching this regexp")
+	grepV := flag.String("grep-v", "", "skip plain-text lines matching this regexp when loading")
+	promoteAfter := flag.Int("promote-after", 0, "promote a warning to an error when more than this many share its message within --promote-window (0 disables)")
+	promoteWindow := flag.Duration("promote-window", time.Hour, "window for --promote-after (0 counts the whole load)")
+	tabName := flag.String("tab", "", "tab to open on, by name")
+	search := flag.String("search", "", "initial search query")
+	unknownLevel := flag.String("unknown-level", "information", "severity for entries whose level isn't recognized: errors, warnings or information")
+	onUpdate := flag.String("on-update", "keep", "where the cursor goes when entries arrive or reload: keep, newest or first (cycle with Ctrl+N)")
+	regexSearch := flag.Bool("regex", false, "treat the search as a case-insensitive regular expression (toggle with Ctrl+X)")
+	queryLang := flag.Bool("query-lang", false, "search with expressions like 'level:error AND (disk OR memory) NOT timeout' instead of plain text")
+	wholeWord := flag.Bool("whole-word", false, "match search terms as whole words only (toggle with Ctrl+O)")
+	from := flag.String("from", "", "initial start date, in --date-format")
+	to := flag.String("to", "", "initial end date, in --date-format")
+	excludeOn := flag.String("exclude-date", "", "initial date to hide entirely, in --date-format")
+	exportPath := flag.String("export", "selection.csv", "default file to export selected rows to (.json for JSON, .html for an HTML report, CSV otherwise)")
+	maxMsgLen := flag.Int("max-msg-len", 0, "truncate messages longer than this many characters when loading (0 disables)")
+	trimSpace := flag.Bool("trim", false, "trim leading and trailing whitespace from messages when loading; the detail view shows them as read")
+	collapseSpaces := flag.Bool("collapse-spaces", false, "like --trim, and also squeeze runs of spaces and tabs inside messages to one space")
+	keepFull := flag.Bool("keep-full", false, "with --max-msg-len, keep the full message for export and copy")
+	columns := flag.String("columns", "", "comma-separated table columns in order: timestamp, level, source, delta, ingested, message")
+	noAltScreen := flag.Bool("no-altscreen", false, "render inline instead of in the alternate screen, leaving the final view in the terminal")
+	mouse := flag.Bool("mouse", false, "capture the mouse so the timestamp column can be resized by dragging its edge (the terminal's own text selection then needs Shift)")
+	printOnExit := flag.String("print-on-exit", "", "on quit, print the filtered rows to stdout as text, csv, json or html (the UI draws on stderr)")
+	quitKey := flag.String("quit-key", "q", `key that quits: "q", "qq" (press twice) or "ctrl+c"`)
+	severityTies := flag.Bool("severity-ties", false, "sort entries that share a timestamp errors first, then warnings, then info (toggle with ⇧S)")
+	zebra := flag.Bool("zebra", false, "give every other table row a subtle background (toggle with Z)")
+	matchDays := flag.Bool("match-days", false, "while searching, show the days with the most matches in the status bar (toggle with #)")
+	showOrigin := flag.Bool("show-origin", false, "show the loaded file and when it was loaded in the status bar and terminal title")
+	clock12 := flag.Bool("12h", false, "show times of day on a 12-hour clock with AM/PM (toggle with Ctrl+T)")
+	tsPrecision := flag.Int("ts-precision", 3, "fractional second digits to show for sub-second timestamps (0-9)")
+	perTabFilters := flag.Bool("per-tab-filters", false, "give each tab its own search and date filters")
+	simpleSplit := flag.Bool("simple-split", false, "parse each line as a timestamp and message split at the first whitespace (same as --parser simple)")
+	parserName := flag.String("parser", "default", "line parser: default, simple, json, syslog or access (Apache/Nginx)")
+	tabs := flag.String("tabs", "", `tabs to show, e.g. "Critical=critical,Errors=error,Debug=debug,All=*"`)
+	refreshInterval := flag.Duration("refresh-interval", 5*time.Second, "how often auto-refresh (Ctrl+R) reloads --file")
+	debug := flag.Bool("debug", false, "show load and filter timings in the status bar")
+	live := flag.Bool("live", false, "apply filters as you type instead of on Enter")
+	gap := flag.Duration("gap", 0, "highlight gaps between consecutive entries longer than this in the All tab (0 disables)")
+	plainExport := flag.Bool("plain-export", false, "export rows only, without the filter and count header")
+	redact := flag.Bool("redact", false, "mask IPv4 addresses, emails and key=value secrets in displayed and exported messages")
+	var redactPatterns []string
+	flag.Func("redact-pattern", "extra regexp to mask with --redact (repeatable)", func(s string) error {
+		redactPatterns = append(redactPatterns, s)
+		return nil
+	})
+	var normalize displayRules
+	flag.Func("normalize", `rewrite displayed messages with "regex=>replacement", e.g. "[0-9]+=>#" (repeatable)`, func(s string) error {
+		rule, err := parseDisplayRule(s)
+		if err == nil {
+			normalize = append(normalize, rule)
+		}
+		return err
+	})
+	searchRaw := flag.Bool("search-raw", false, "with --redact, let search match the original unredacted messages")
+	idleTimeout := flag.Duration("idle-timeout", 0, "quit after this long without a keypress or new entries (0 never does)")
+	pollInterval := flag.Duration("poll-interval", time.Second, "how often to check followed files for new content (min 100ms)")
+	flag.Parse()
+
+	if err := validateDateFormat(*dateFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if *printOnExit != "" && !slices.Contains(outputFormats, *printOnExit) {
+		fmt.Fprintf(os.Stderr, "Error: --print-on-exit must be one of %s\n", strings.Join(outputFormats, ", "))
+		os.Exit(2)
+	}
+	if !slices.Contains(cursorModes, *onUpdate) {
+		fmt.Fprintf(os.Stderr, "Error: --on-update must be one of %s\n", strings.Join(cursorModes, ", "))
+		os.Exit(2)
+	}
+	if *regexSearch && *queryLang {
+		fmt.Fprintf(os.Stderr, "Error: --regex and --query-lang can't be combined\n")
+		os.Exit(2)
+	}
+	if *regexSearch && *search != "" {
+		if _, err := regexp.Compile(*search); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --search regex: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if !slices.Contains(quitKeys, *quitKey) {
+		fmt.Fprintf(os.Stderr, "Error: --quit-key must be one of %s\n", strings.Join(quitKeys, ", "))
+		os.Exit(2)
+	}
+
+	var redaction redactor
+	if *redact || len(redactPatterns) > 0 {
+		var err error
+		if redaction, err = newRedactor(redactPatterns); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --redact-pattern: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	placeholder := layoutLabel.Replace(*dateFormat)
+
+	if *simpleSplit {
+		*parserName = "simple"
+	}
+	parser, err := lookupParser(*parserName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	parseLogLine = lineParser(parser)
+	if unknownSeverity, err = parseSeverityName(*unknownLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --unknown-level: %v\n", err)
+		os.Exit(2)
+	}
+	if *grep != "" || *grepV != "" {
+		var include, exclude *regexp.Regexp
+		var err error
+		if *grep != "" {
+			if include, err = regexp.Compile(*grep); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --grep: %v\n", err)
+				os.Exit(2)
+			}
+		}
+		if *grepV != "" {
+			if exclude, err = regexp.Compile(*grepV); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid --grep-v: %v\n", err)
+				os.Exit(2)
+			}
+		}
+		parseLogLine = grepLines(parseLogLine, include, exclude)
+	}
+
+	categories := defaultCategories
+	if *tabs != "" {
+		var err error
+		if categories, err = parseCategories(*tabs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	var columnNames []string
+	if *columns != "" {
+		var err error
+		if columnNames, err = parseColumns(*columns); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	searchBox := textinput.New()
+	searchBox.Placeholder = searchPlaceholder
+	searchBox.Width = 30
+
+	startDate := textinput.New()
+	startDate.Placeholder = placeholder
+	startDate.Width = max(12, len(placeholder))
+
+	endDate := textinput.New()
+	endDate.Placeholder = placeholder
+	endDate.Width = max(12, len(placeholder))
+
+	excludeDate := textinput.New()
+	excludeDate.Placeholder = placeholder
+	excludeDate.Width = max(12, len(placeholder))
+
+	findBox := textinput.New()
+	findBox.Placeholder = "text to find"
+	findBox.Width = 30
+
+	exportBox := textinput.New()
+	exportBox.Placeholder = "file.csv or file.json"
+	exportBox.Width = 40
 
 	m := model{
-		searchBox: searchBox,
-		startDate: startDate,
-		endDate:   endDate,
-		errors: []Log{
-			{timestamp: "2024-10-01", message: "authentication failure"},
-			{timestamp: "2024-10-05", message: "out of memory"},
-		},
-		warnings: []Log{
-			{timestamp: "2024-10-02", message: "disk usage high"},
-			{timestamp: "2024-10-06", message: "CPU usage high"},
-		},
-		info: []Log{
-			{timestamp: "2024-10-01", message: "service started"},
-			{timestamp: "2024-10-04", message: "configuration loaded"},
-		},
-	}
-
-	m.applyFilters() // Initialize filtered logs
-
-	p := tea.NewProgram(&m)
+		searchBox:       searchBox,
+		exportBox:       exportBox,
+		findBox:         findBox,
+		startDate:       startDate,
+		endDate:         endDate,
+		excludeDate:     excludeDate,
+		dateFormat:      *dateFormat,
+		pollInterval:    *pollInterval,
+		exportPath:      *exportPath,
+		plainExport:     *plainExport,
+		redact:          redaction,
+		searchRaw:       *searchRaw,
+		normalize:       normalize,
+		selected:        make(map[int]bool),
+		gap:             *gap,
+		liveFilter:      *live,
+		maxMsgLen:       *maxMsgLen,
+		keepFull:        *keepFull,
+		trimSpace:       *trimSpace,
+		showMatchDays:   *matchDays,
+		collapseSpaces:  *collapseSpaces,
+		filtersVisible:  true,
+		columns:         columnNames,
+		noAltScreen:     *noAltScreen,
+		perTabFilters:   *perTabFilters,
+		categories:      categories,
+		debug:           *debug,
+		refreshInterval: max(*refreshInterval, minPollInterval),
+		tailBytes:       *tailBytes,
+		tabFilters:      make([]tabFilters, len(categories)),
+		tabCursors:      make([]int, len(categories)),
+		highlight:       true,
+		zebra:           *zebra,
+		severityTies:    *severityTies,
+		wholeWord:       *wholeWord,
+		idleTimeout:     *idleTimeout,
+		queryLang:       *queryLang,
+		regexSearch:     *regexSearch,
+		onUpdate:        slices.Index(cursorModes, *onUpdate),
+		timeDetail:      true,
+		tsPrecision:     min(max(*tsPrecision, 0), 9),
+		clock12:         *clock12,
+		quitKey:         *quitKey,
+	}
+
+	for _, arg := range [][2]string{{"file", *file}, {"dir", *dir}, {"tabs", *tabs}} {
+		if arg[1] != "" {
+			m.launchArgs = append(m.launchArgs, "--"+arg[0], arg[1])
+		}
+	}
+	if *dateFormat != canonicalDateFormat {
+		m.launchArgs = append(m.launchArgs, "--date-format", *dateFormat)
+	}
+	if *parserName != "default" {
+		m.launchArgs = append(m.launchArgs, "--parser", *parserName)
+	}
+	if *tailBytes > 0 {
+		m.launchArgs = append(m.launchArgs, "--tail-bytes", strconv.FormatInt(*tailBytes, 10))
+	}
+	for _, arg := range [][2]string{{"grep", *grep}, {"grep-v", *grepV}} {
+		if arg[1] != "" {
+			m.launchArgs = append(m.launchArgs, "--"+arg[0], arg[1])
+		}
+	}
+
+	m.loadStart = time.Now()
+	var logs []Log
+	if *file == "" && *dir == "" && !*stdin {
+		logs = sampleLogs
+	}
+
+	fifo := *file != "" && isFIFO(*file)
+	local := *file != "" && !isURL(*file) && !fifo
+	if isURL(*file) || fifo || *dir != "" || *stdin || (local && *progressive) {
+		m.live = make(chan tea.Msg)
+		m.loadOnly = !isURL(*file) && !fifo && *dir == "" && !*stdin
+	}
+
+	if fifo {
+		// A pipe has no history to load or reload; just follow the writer
+		go followFIFO(*file, m.live)
+	}
+
+	if isURL(*file) {
+		body, err := openURL(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		go streamLines(body, *file, m.live)
+	}
+
+	if *dir != "" {
+		watcher, err := newDirWatcher(*dir, m.live)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer watcher.Close()
+
+		watcher.tailBytes = *tailBytes
+		dirLogs, err := watcher.load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		logs = append(logs, dirLogs...)
+		m.watcher = watcher
+	}
+	m.addLogs(logs)
+
+	if local {
+		m.filePath = *file
+		if *progressive {
+			f, err := openTail(*file, *tailBytes)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			m.loading = true
+			go loadProgressively(f, m.live)
+		} else if _, _, err := m.reloadFile(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *showOrigin {
+		m.origin = describeOrigin(*file, *dir, *stdin)
+	}
+	if *stdin {
+		// Follow stdin once history is loaded; EOF just ends the follow
+		go streamLines(os.Stdin, "stdin", m.live)
+	}
+	if *tailBytes > 0 && (*dir != "" || m.filePath != "") {
+		m.status = fmt.Sprintf("Loaded only the last %d bytes of each file (--tail-bytes)", *tailBytes)
+	}
+	m.promoteAfter, m.promoteWindow = *promoteAfter, *promoteWindow
+	if !m.loading {
+		m.finishLoad()
+	}
+
+	if *tabName != "" {
+		m.activeTab = m.findTab(func(c category) bool { return strings.EqualFold(c.name, *tabName) })
+		if m.activeTab < 0 {
+			fmt.Fprintf(os.Stderr, "Error: no tab named %q\n", *tabName)
+			os.Exit(2)
+		}
+	}
+	m.searchBox.SetValue(*search)
+	m.startDate.SetValue(*from)
+	m.endDate.SetValue(*to)
+	m.excludeDate.SetValue(*excludeOn)
+	for _, bound := range []string{*from, *to, *excludeOn} {
+		if _, ok := normalizeDate(bound, *dateFormat); !ok {
+			fmt.Fprintf(os.Stderr, "Error: %q doesn't match --date-format\n", bound)
+			os.Exit(2)
+		}
+	}
+	m.applied = m.filterState() // Flags aren't an undoable change
+	m.applyFilters()            // Initialize filtered logs
+
+	var options []tea.ProgramOption
+	if *mouse {
+		// Off by default, as capturing the mouse stops plain click-and-drag
+		// text selection in most terminals
+		options = append(options, tea.WithMouseCellMotion())
+	}
+	if *printOnExit != "" {
+		// Keep stdout for the printed rows so it can be piped
+		options = append(options, tea.WithOutput(os.Stderr))
+	}
+	p := tea.NewProgram(&m, options...)
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v", err)
 		os.Exit(1)
 	}
+	if *printOnExit != "" {
+		if err := writeLogs(os.Stdout, *printOnExit, m.redact.logs(m.filteredLogs), nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
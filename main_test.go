@@ -0,0 +1,406 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// newTestModel returns a model holding logs, filtered and ready to render.
+func newTestModel(logs []Log) *model {
+	m := &model{
+		dateFormat:     canonicalDateFormat,
+		categories:     defaultCategories,
+		selected:       make(map[int]bool),
+		tabFilters:     make([]tabFilters, len(defaultCategories)),
+		tabCursors:     make([]int, len(defaultCategories)),
+		timeDetail:     true,
+		width:          120,
+		height:         40,
+		activeTab:      len(defaultCategories) - 1, // All
+		filtersVisible: true,
+	}
+	m.addLogs(logs)
+	m.applyFilters()
+	m.initLogTable()
+	return m
+}
+
+// messages lists the messages of logs in order.
+func messages(logs []Log) []string {
+	var msgs []string
+	for _, log := range logs {
+		msgs = append(msgs, log.message)
+	}
+	return msgs
+}
+
+func TestSinceLastErrorWithISOTimestamps(t *testing.T) {
+	m := newTestModel([]Log{
+		{timestamp: "2024-10-05T11:00:00Z", message: "before", severity: Information},
+		{timestamp: "2024-10-05T12:00:00.900Z", message: "last error", severity: Errors},
+		{timestamp: "2024-10-05 09:00:00", message: "earlier error", severity: Errors},
+		{timestamp: "2024-10-05T11:59:59.500Z", message: "just before", severity: Warnings},
+		{timestamp: "2024-10-05T12:30:00Z", message: "after", severity: Information},
+	})
+	m.sinceLastError()
+	got := messages(m.filteredLogs)
+	want := []string{"last error", "after"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("since last error shows %q, want %q", got, want)
+	}
+}
+
+func TestSubSecondTimestamps(t *testing.T) {
+	early := Log{id: 1, timestamp: "2024-10-01T12:00:00.123", message: "early"}
+	late := Log{id: 2, timestamp: "2024-10-01T12:00:00.124", message: "late"}
+	early.at, late.at = parseTimestamp(early.timestamp), parseTimestamp(late.timestamp)
+
+	for _, input := range [][]Log{{early, late}, {late, early}} {
+		logs := append([]Log(nil), input...)
+		sortLogs(logs, false)
+		if logs[0].message != "early" || logs[1].message != "late" {
+			t.Errorf("sorting %q gave %q", messages(input), messages(logs))
+		}
+	}
+	if !early.before(late) || late.before(early) {
+		t.Error(".123 should sort before .124")
+	}
+
+	tests := []struct {
+		precision int
+		want      string
+	}{
+		{0, "2024-10-01 12:00:00"},
+		{3, "2024-10-01 12:00:00.123"},
+	}
+	for _, tt := range tests {
+		m := &model{tsPrecision: tt.precision}
+		if got := m.formatTimestamp(early); got != tt.want {
+			t.Errorf("--ts-precision %d: got %q, want %q", tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestReloadFileKeepsRepeatedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	line := "2024-10-01 12:00:00 ERROR connection refused\n"
+	if err := os.WriteFile(path, []byte(line+line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(nil)
+	m.filePath = path
+	if added, _, err := m.reloadFile(); err != nil || added != 2 {
+		t.Fatalf("first load added %d (%v), want 2", added, err)
+	}
+
+	appendFile(t, path, line)
+	added, removed, err := m.reloadFile()
+	if err != nil || added != 1 || removed != 0 {
+		t.Fatalf("reload after an identical line: +%d -%d (%v), want +1 -0", added, removed, err)
+	}
+	if len(m.errors) != 3 {
+		t.Fatalf("have %d entries, want 3", len(m.errors))
+	}
+
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if added, removed, _ := m.reloadFile(); added != 0 || removed != 2 {
+		t.Errorf("reload after dropping two copies: +%d -%d, want +0 -2", added, removed)
+	}
+}
+
+func TestProgressiveLoadThenReloadKeepsIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	content := "2024-10-01 12:00:00 ERROR a rather long message that gets cut\n" +
+		"2024-10-01 12:00:01 INFO   padded message  \n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(nil)
+	m.filePath, m.maxMsgLen, m.trimSpace = path, 10, true
+	m.loading, m.loadOnly = true, true
+	m.live = make(chan tea.Msg)
+
+	logs, err := loadFile(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Update(logsMsg(logs))
+	m.Update(loadDoneMsg{})
+	before := m.nextID
+
+	added, removed, err := m.reloadFile()
+	if err != nil || added != 0 || removed != 0 {
+		t.Errorf("reload after a progressive load: +%d -%d (%v), want +0 -0", added, removed, err)
+	}
+	if m.nextID != before {
+		t.Errorf("reload gave out new IDs (%d, was %d)", m.nextID, before)
+	}
+}
+
+// ansiSequence matches the SGR escapes lipgloss styles text with.
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// styledSpans strips the escapes from s, returning the plain text and the
+// byte range of each styled run within it.
+func styledSpans(s string) (plain string, spans [][2]int) {
+	var b strings.Builder
+	start := -1
+	for len(s) > 0 {
+		loc := ansiSequence.FindStringIndex(s)
+		if loc == nil {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:loc[0]])
+		if seq := s[loc[0]:loc[1]]; seq == "\x1b[0m" {
+			if start >= 0 {
+				spans = append(spans, [2]int{start, b.Len()})
+			}
+			start = -1
+		} else if start < 0 {
+			start = b.Len()
+		}
+		s = s[loc[1]:]
+	}
+	return b.String(), spans
+}
+
+func TestHighlightMatchesMultibyte(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI)
+	match := lipgloss.NewStyle().Reverse(true)
+
+	tests := []struct {
+		text, query string
+		want        []string
+	}{
+		{"Café au lait at the CAFÉ", "café", []string{"Café", "CAFÉ"}},
+		{"Visit CAFÉ now", "Café", []string{"CAFÉ"}},
+		{"数据库连接失败，数据库重试中", "数据库", []string{"数据库", "数据库"}},
+		{"错误：磁盘已满", "磁盘", []string{"磁盘"}},
+	}
+	for _, tt := range tests {
+		out := highlightMatches(tt.text, tt.query, false, lipgloss.NewStyle(), match, math.MaxInt)
+		plain, spans := styledSpans(out)
+		if plain != tt.text {
+			t.Errorf("%q: stripped output %q differs", tt.text, plain)
+			continue
+		}
+		if len(spans) != len(tt.want) {
+			t.Errorf("%q: %d matches highlighted, want %d", tt.text, len(spans), len(tt.want))
+			continue
+		}
+		for i, span := range spans {
+			if !utf8.ValidString(plain[:span[0]]) || !utf8.ValidString(plain[span[0]:span[1]]) {
+				t.Errorf("%q: match %d at bytes %v splits a rune", tt.text, i, span)
+			}
+			if got := plain[span[0]:span[1]]; got != tt.want[i] {
+				t.Errorf("%q: match %d is %q, want %q", tt.text, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHelpFooterFitsWidth(t *testing.T) {
+	noSpace := strings.NewReplacer(" ", "", "\n", "")
+	for _, width := range []int{20, 80, 200} {
+		m := newTestModel(nil)
+		m.width = width
+		footer := m.renderHelpFooter()
+		for _, line := range strings.Split(footer, "\n") {
+			if w := lipgloss.Width(line); w > m.width {
+				t.Errorf("width %d: line %q is %d wide", width, line, w)
+			}
+		}
+		flat := noSpace.Replace(ansiSequence.ReplaceAllString(footer, ""))
+		for _, item := range m.helpItems() {
+			if !strings.Contains(flat, noSpace.Replace(item.key+item.description)) {
+				t.Errorf("width %d: %s %s was dropped", width, item.key, item.description)
+			}
+		}
+	}
+}
+
+func TestHelpFooterShowsEssentialKeys(t *testing.T) {
+	m := newTestModel(nil)
+	items := m.helpItems()
+	if len(items) != len(footerKeys)+1 {
+		t.Errorf("footer has %d items, want %d essentials and F1", len(items), len(footerKeys))
+	}
+	if last := items[len(items)-1]; last.key != "F1" {
+		t.Errorf("footer ends with %s %s, want F1 All Keys", last.key, last.description)
+	}
+	if lines := strings.Count(m.renderHelpFooter(), "\n") + 1; lines > 2 {
+		t.Errorf("footer takes %d lines at width %d", lines, m.width)
+	}
+}
+
+func TestRegexSearchWholeWords(t *testing.T) {
+	logs := []Log{
+		{message: "disk error"},
+		{message: "disk errors piling up"},
+		{message: "terror alert"},
+	}
+	m := newTestModel(nil)
+	m.searchBox = textinput.New()
+	m.searchBox.SetValue("err(or)?")
+	m.regexSearch, m.wholeWord = true, true
+	m.compileSearch()
+	if m.regexErr != nil {
+		t.Fatal(m.regexErr)
+	}
+	got := messages(m.matchRegex(logs))
+	if strings.Join(got, "|") != "disk error" {
+		t.Errorf("whole-word regex matched %q, want only \"disk error\"", got)
+	}
+}
+
+func TestUntimestampedEntriesSurviveDateBounds(t *testing.T) {
+	logs := []Log{
+		{timestamp: "2024-10-01 09:00:00", message: "too early"},
+		{message: "no time a"},
+		{timestamp: "2024-10-02 12:00:00", message: "in range"},
+		{message: "no time b"},
+		{timestamp: "2024-10-04 09:00:00", message: "too late"},
+	}
+	for i := range logs {
+		logs[i].at = parseTimestamp(logs[i].timestamp)
+	}
+	got := messages(filterLogs(logs, "", false, "2024-10-02", "2024-10-03", ""))
+	want := []string{"no time a", "in range", "no time b"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("filtered to %q, want %q", got, want)
+	}
+}
+
+func TestUntimestampedEntriesSortLast(t *testing.T) {
+	m := newTestModel([]Log{
+		{message: "no time a", severity: Information},
+		{timestamp: "2024-10-02 12:00:00", message: "second", severity: Errors},
+		{message: "no time b", severity: Errors},
+		{timestamp: "2024-10-01 12:00:00", message: "first", severity: Warnings},
+		{message: "no time c", severity: Warnings},
+	})
+	got := messages(m.filteredLogs)
+	want := []string{"first", "second", "no time a", "no time b", "no time c"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("sorted to %q, want %q", got, want)
+	}
+	for i := 2; i < len(m.filteredLogs); i++ {
+		if cell := m.timestampCell(i); cell != noTimestamp {
+			t.Errorf("row %d shows timestamp %q, want %q", i, cell, noTimestamp)
+		}
+	}
+}
+
+func TestBalanceColumns(t *testing.T) {
+	names := []string{"timestamp", "level", "source", "message"}
+	for _, available := range []int{10, 40, 80, 200} {
+		widths := map[string]int{}
+		for name, width := range columnWidths {
+			widths[name] = width
+		}
+		full := widths["timestamp"]
+		dateOnly := balanceColumns(names, widths, available)
+
+		for _, name := range names {
+			if widths[name] < 0 {
+				t.Errorf("available %d: %s is %d wide", available, name, widths[name])
+			}
+			if least, ok := columnMinWidths[name]; ok && widths[name] < least {
+				t.Errorf("available %d: %s is %d wide, under its minimum %d", available, name, widths[name], least)
+			}
+		}
+		if widths["message"] < 1 {
+			t.Errorf("available %d: message is %d wide", available, widths["message"])
+		}
+		// The time of day goes before the message drops under its minimum
+		if widths["message"] < minMessageWidth && (!dateOnly || widths["timestamp"] > columnMinWidths["timestamp"]) {
+			t.Errorf("available %d: message cut to %d while timestamps are %d wide", available, widths["message"], widths["timestamp"])
+		}
+		if dateOnly == (widths["timestamp"] == full) {
+			t.Errorf("available %d: dateOnly %v with timestamps %d wide", available, dateOnly, widths["timestamp"])
+		}
+	}
+
+	widths := map[string]int{}
+	for name, width := range columnWidths {
+		widths[name] = width
+	}
+	if balanceColumns(names, widths, 200) {
+		t.Error("200 columns shouldn't cut timestamps to their date")
+	}
+}
+
+func TestZoomWithISOTimestamps(t *testing.T) {
+	m := newTestModel([]Log{
+		{timestamp: "2024-10-05T11:59:59Z", message: "before"},
+		{timestamp: "2024-10-05T12:00:00Z", message: "start"},
+		{timestamp: "2024-10-05T12:34:10Z", message: "selected"},
+		{timestamp: "2024-10-05T12:34:59.750Z", message: "minute end"},
+		{timestamp: "2024-10-05T12:59:59.500Z", message: "hour end"},
+		{timestamp: "2024-10-05T13:00:00Z", message: "after"},
+	})
+	selectEntry := func() {
+		for i, log := range m.filteredLogs {
+			if log.message == "selected" {
+				m.setCursor(i)
+				return
+			}
+		}
+		t.Fatal("selected entry not shown")
+	}
+
+	tests := []struct {
+		unit time.Duration
+		want []string
+	}{
+		{time.Minute, []string{"selected", "minute end"}},
+		{time.Hour, []string{"start", "selected", "minute end", "hour end"}},
+	}
+	for _, tt := range tests {
+		selectEntry()
+		m.zoom(tt.unit)
+		if got := messages(m.filteredLogs); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("zoom to the %v shows %q, want %q", tt.unit, got, tt.want)
+		}
+		if m.filteredLogs[m.logTable.Cursor()].message != "selected" {
+			t.Errorf("zoom to the %v moved the cursor off the entry", tt.unit)
+		}
+		m.zoom(tt.unit)
+		if len(m.filteredLogs) != 6 {
+			t.Errorf("zooming out shows %d entries, want 6", len(m.filteredLogs))
+		}
+	}
+}
+
+func TestEndDateTakesInItsWholeDay(t *testing.T) {
+	logs := []Log{
+		{timestamp: "2024-10-02 23:00:00", message: "day before"},
+		{timestamp: "2024-10-03", message: "date only"},
+		{timestamp: "2024-10-03 09:00:00", message: "morning"},
+		{timestamp: "2024-10-03T23:59:59.999Z", message: "last moment"},
+		{timestamp: "2024-10-04 00:00:00", message: "next day"},
+	}
+	for i := range logs {
+		logs[i].at = parseTimestamp(logs[i].timestamp)
+	}
+	got := messages(filterLogs(logs, "", false, "2024-10-03", "2024-10-03", ""))
+	want := []string{"date only", "morning", "last moment"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("end date 2024-10-03 shows %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// displayRule rewrites matches of pattern in displayed messages, so that
+// messages differing only in variable parts line up.
+type displayRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// displayRules are applied in order. Search and the detail view always use
+// the original message.
+type displayRules []displayRule
+
+// parseDisplayRule parses a --normalize "regex=>replacement" spec.
+func parseDisplayRule(spec string) (displayRule, error) {
+	expr, replacement, ok := strings.Cut(spec, "=>")
+	if !ok {
+		return displayRule{}, fmt.Errorf("%q: expected regex=>replacement", spec)
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return displayRule{}, err
+	}
+	return displayRule{pattern: pattern, replacement: replacement}, nil
+}
+
+func (r displayRules) apply(text string) string {
+	for _, rule := range r {
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+	return text
+}
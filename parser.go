@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogParser turns one line of a plain-text source into an entry. Parse
+// returns the entry, its severity and whether the line held an entry at all.
+type LogParser interface {
+	Parse(line string) (Log, int, bool)
+}
+
+// parserFunc adapts a line-parsing function to LogParser.
+type parserFunc func(string) (Log, bool)
+
+func (f parserFunc) Parse(line string) (Log, int, bool) {
+	log, ok := f(line)
+	return log, log.severity, ok
+}
+
+// parsers are the parsers selectable with --parser, by name.
+var parsers = map[string]LogParser{
+	"default": parserFunc(parseLine),
+	"simple":  parserFunc(parseSimpleLine),
+	"json":    jsonParser{},
+	"syslog":  syslogParser{},
+	"access":  accessParser{},
+}
+
+// registerParser makes p selectable with --parser name, replacing any
+// parser already registered under that name.
+func registerParser(name string, p LogParser) {
+	parsers[name] = p
+}
+
+// lookupParser returns the parser registered as name.
+func lookupParser(name string) (LogParser, error) {
+	if p, ok := parsers[name]; ok {
+		return p, nil
+	}
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown parser %q (known: %s)", name, strings.Join(names, ", "))
+}
+
+// lineParser adapts p to the function form the line readers call. A
+// severity outside Errors..Information goes where unknown levels do.
+func lineParser(p LogParser) func(string) (Log, bool) {
+	return func(line string) (Log, bool) {
+		log, severity, ok := p.Parse(line)
+		if severity < Errors || severity > Information {
+			severity, log.unknown = unknownSeverity, true
+		}
+		log.severity = severity
+		return log, ok
+	}
+}
+
+// jsonParser accepts only single-line JSON objects.
+type jsonParser struct{}
+
+func (jsonParser) Parse(line string) (Log, int, bool) {
+	if !isJSONLine(line) {
+		return Log{}, 0, false
+	}
+	log, ok := parseJSONLine(line)
+	return log, log.severity, ok
+}
+
+// syslogPattern matches BSD syslog lines such as
+// "<34>Oct  1 12:00:00 host sshd[42]: message", the priority being optional.
+var syslogPattern = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[(\d+)\])?: ?(.*)$`)
+
+// syslogParser parses BSD syslog (RFC 3164) lines. The priority, when
+// present, sets the severity; otherwise a leading level word does.
+type syslogParser struct{}
+
+func (syslogParser) Parse(line string) (Log, int, bool) {
+	match := syslogPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return Log{}, 0, false
+	}
+	pri, stamp, host, app, pid, message := match[1], match[2], match[3], match[4], match[5], match[6]
+
+	log := Log{timestamp: stamp, message: message, severity: Information}
+	log.fields = map[string]string{"host": host, "app": app}
+	if pid != "" {
+		log.fields["pid"] = pid
+	}
+
+	// Syslog omits the year; assume the most recent one that isn't ahead
+	if t, err := time.ParseInLocation("Jan _2 15:04:05", stamp, time.Local); err == nil {
+		now := time.Now()
+		t = t.AddDate(now.Year(), 0, 0)
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		log.timestamp = t.Format(canonicalTimeFormat)
+	}
+
+	if n, err := strconv.Atoi(pri); err == nil {
+		// The low three bits are the RFC 5424 severity, 0 (emergency) to 7 (debug)
+		switch level := n % 8; {
+		case level <= 3:
+			log.severity, log.level = Errors, "error"
+		case level == 4:
+			log.severity, log.level = Warnings, "warning"
+		default:
+			log.level = "info"
+		}
+	} else if word, _, _ := strings.Cut(message, " "); word != "" {
+		if sev, ok := parseLevel(word); ok {
+			log.severity, log.level = sev, levelToken(word)
+		}
+	}
+	return log, log.severity, true
+}
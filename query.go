@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// queryExpr is a search expression parsed with --query-lang, such as
+// `level:error AND (disk OR memory) NOT timeout`.
+type queryExpr interface {
+	// match reports whether log matches, text being its searchable message
+	match(log Log, text string) bool
+}
+
+type andExpr struct{ left, right queryExpr }
+type orExpr struct{ left, right queryExpr }
+type notExpr struct{ expr queryExpr }
+
+// termExpr matches value within field, or within the message when field is
+// empty. Matching ignores case.
+type termExpr struct{ field, value string }
+
+func (e andExpr) match(log Log, text string) bool {
+	return e.left.match(log, text) && e.right.match(log, text)
+}
+
+func (e orExpr) match(log Log, text string) bool {
+	return e.left.match(log, text) || e.right.match(log, text)
+}
+
+func (e notExpr) match(log Log, text string) bool {
+	return !e.expr.match(log, text)
+}
+
+func (e termExpr) match(log Log, text string) bool {
+	switch strings.ToLower(e.field) {
+	case "", "message", "msg":
+		return strings.Contains(strings.ToLower(text), e.value)
+	case "level":
+		// "level:warn" matches the warning severity as well as a raw "warn"
+		return strings.ToLower(log.level) == e.value || strings.HasPrefix(severityNames[log.severity], e.value)
+	case "timestamp", "time":
+		return strings.HasPrefix(strings.ToLower(log.timestamp), e.value)
+	case "source":
+		return strings.Contains(strings.ToLower(log.source), e.value)
+	}
+	// Anything else names a structured field, such as host
+	value, ok := log.fields[e.field]
+	return ok && strings.Contains(strings.ToLower(value), e.value)
+}
+
+// queryToken is a word or parenthesis of a query. Quoted words are never
+// operators or field selectors.
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeQuery splits query into words and parentheses. Double quotes
+// group words, including spaces, into one.
+func tokenizeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{text: string(r)})
+			i++
+		default:
+			var b strings.Builder
+			token := queryToken{quoted: r == '"'}
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if runes[i] != '"' {
+					b.WriteRune(runes[i])
+					i++
+					continue
+				}
+				end := strings.IndexRune(string(runes[i+1:]), '"')
+				if end < 0 {
+					return nil, errors.New("unclosed quote")
+				}
+				quoted := []rune(string(runes[i+1:])[:end])
+				b.WriteString(string(quoted))
+				i += len(quoted) + 2
+			}
+			token.text = b.String()
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// queryParser parses tokens by recursive descent. OR binds loosest, then
+// AND, which adjacent terms imply, then NOT.
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// parseQueryExpr parses a --query-lang search expression.
+func parseQueryExpr(query string) (queryExpr, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+	p := &queryParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+// peek reports whether the next token is the unquoted operator or
+// parenthesis op.
+func (p *queryParser) peek(op string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == op
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.tokens) && !p.peek(")") && !p.peek("OR") {
+		if p.peek("AND") {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("query ends early")
+	}
+	switch {
+	case p.peek("NOT"):
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	case p.peek("("):
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return expr, nil
+	case p.peek(")"), p.peek("AND"), p.peek("OR"):
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+	term := termExpr{value: strings.ToLower(token.text)}
+	if field, value, ok := strings.Cut(token.text, ":"); ok && !token.quoted && field != "" && value != "" {
+		term = termExpr{field: field, value: strings.ToLower(value)}
+	}
+	return term, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import "regexp"
+
+// redactMask replaces every redacted match.
+const redactMask = "***"
+
+// defaultRedactPatterns mask IPv4 addresses, email addresses and secrets
+// passed as key=value pairs.
+var defaultRedactPatterns = []string{
+	`\b(?:\d{1,3}\.){3}\d{1,3}\b`,
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	`(?i)\b(?:token|secret|password|passwd|api[_-]?key)=\S+`,
+}
+
+// redactor masks sensitive text in displayed and exported messages. A nil
+// redactor leaves text unchanged.
+type redactor []*regexp.Regexp
+
+// newRedactor compiles the default patterns followed by extra.
+func newRedactor(extra []string) (redactor, error) {
+	var r redactor
+	for _, pattern := range append(defaultRedactPatterns, extra...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, re)
+	}
+	return r, nil
+}
+
+func (r redactor) apply(text string) string {
+	for _, re := range r {
+		text = re.ReplaceAllString(text, redactMask)
+	}
+	return text
+}
+
+// logs returns copies of logs with their messages redacted.
+func (r redactor) logs(logs []Log) []Log {
+	if r == nil {
+		return logs
+	}
+	redacted := make([]Log, len(logs))
+	for i, log := range logs {
+		log.message = r.apply(log.message)
+		log.full = r.apply(log.full)
+		redacted[i] = log
+	}
+	return redacted
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// streamFlushInterval bounds how long streamed lines wait before being
+// delivered, so slow streams still show up promptly.
+const streamFlushInterval = 200 * time.Millisecond
+
+// maxStreamBatch caps how many lines are delivered at once.
+const maxStreamBatch = 1000
+
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// openURL starts fetching url, failing on non-2xx responses.
+func openURL(url string) (io.ReadCloser, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// streamLines parses body line by line and delivers entries to out in
+// batches. It keeps reading for as long as the server holds the response
+// open, which lets chunked responses be tailed like a followed file.
+func streamLines(body io.ReadCloser, source string, out chan<- tea.Msg) {
+	defer body.Close()
+
+	lines := make(chan string)
+	var scanErr error
+	go func() {
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		scanErr = scanner.Err()
+		close(lines)
+	}()
+
+	ticker := time.NewTicker(streamFlushInterval)
+	defer ticker.Stop()
+
+	var batch []Log
+	first := true
+	flush := func() {
+		if len(batch) > 0 {
+			out <- logsMsg(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush()
+				if scanErr != nil {
+					out <- sourceErrMsg{err: scanErr}
+				}
+				return
+			}
+			if first {
+				line = strings.TrimPrefix(line, utf8BOM)
+				first = false
+			}
+			if log, ok := parseLogLine(strings.TrimSuffix(line, "\r")); ok {
+				log.source = source
+				batch = append(batch, log)
+			}
+			if len(batch) >= maxStreamBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
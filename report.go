@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+// buildSummaryReport describes everything loaded in a few lines for status
+// updates: the time span, then one line per severity with the most common
+// error message.
+func (m *model) buildSummaryReport() string {
+	var b strings.Builder
+	logs := m.allLogs()
+	first, last := "", ""
+	for _, log := range logs {
+		if log.timestamp == "" {
+			continue
+		}
+		if first == "" {
+			first = log.timestamp
+		}
+		last = log.timestamp
+	}
+	switch {
+	case first == "":
+		fmt.Fprintf(&b, "Log report: %d entries, no timestamps\n", len(logs))
+	case first == last:
+		fmt.Fprintf(&b, "Log report: %d entries at %s\n", len(logs), first)
+	default:
+		fmt.Fprintf(&b, "Log report: %d entries from %s to %s", len(logs), first, last)
+		if from, to := parseTimestamp(first), parseTimestamp(last); !from.IsZero() && !to.IsZero() {
+			fmt.Fprintf(&b, " (%s)", formatGap(to.Sub(from)))
+		}
+		b.WriteString("\n")
+	}
+
+	for sev, count := range []int{len(m.errors), len(m.warnings), len(m.info)} {
+		fmt.Fprintf(&b, "%s: %d", severityNames[sev], count)
+		if sev == Errors && count > 0 {
+			top := summarizeErrors(m.errors)[0]
+			fmt.Fprintf(&b, ", top %q ×%d", m.redact.apply(top.pattern), top.count)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// copyReport copies the summary report, writing it to a file in the working
+// directory instead when there's no clipboard.
+func (m *model) copyReport() {
+	report := m.buildSummaryReport()
+	if err := clipboard.WriteAll(report); err == nil {
+		m.status = "Copied report"
+		return
+	}
+	path := "log-report-" + time.Now().Format("20060102-150405") + ".txt"
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		m.status = "Report failed: " + err.Error()
+		return
+	}
+	m.status = "No clipboard; wrote report to " + path
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// windowsTimeLayouts are the timestamp forms found in Event Viewer and
+// PowerShell exports.
+var windowsTimeLayouts = []string{
+	time.RFC3339Nano,
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// windowsTimestamp converts an exported time to the internal sortable form,
+// leaving it untouched if it can't be parsed.
+func windowsTimestamp(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range windowsTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02 15:04:05")
+		}
+	}
+	return value
+}
+
+// windowsSeverity maps an event level, by name or number, to a severity.
+func windowsSeverity(level string) int {
+	level = strings.TrimSpace(level)
+	if n, err := strconv.Atoi(level); err == nil {
+		switch n {
+		case 1, 2:
+			return Errors
+		case 3:
+			return Warnings
+		}
+		return Information
+	}
+	switch strings.ToLower(level) {
+	case "critical", "error":
+		return Errors
+	case "warning":
+		return Warnings
+	}
+	return Information
+}
+
+// csvColumn returns the index of the first header matching one of names.
+func csvColumn(header []string, names ...string) int {
+	for _, name := range names {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isWindowsCSV(text string) bool {
+	line, _, _ := strings.Cut(text, "\n")
+	header, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return false
+	}
+	return csvColumn(header, "LevelDisplayName", "Level") >= 0 &&
+		csvColumn(header, "TimeCreated", "Date and Time") >= 0
+}
+
+// parseWindowsCSV reads an Event Viewer or Export-Csv event export.
+func parseWindowsCSV(text, source string) ([]Log, error) {
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	levelCol := csvColumn(header, "LevelDisplayName", "Level")
+	timeCol := csvColumn(header, "TimeCreated", "Date and Time")
+	messageCol := csvColumn(header, "Message", "Description")
+	providerCol := csvColumn(header, "ProviderName", "Source")
+
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var logs []Log
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		message := field(record, messageCol)
+		if messageCol < 0 && len(record) > len(header) {
+			// Event Viewer puts the description in an unnamed last column
+			message = record[len(record)-1]
+		}
+		logs = append(logs, Log{
+			timestamp: windowsTimestamp(field(record, timeCol)),
+			message:   strings.Join(strings.Fields(message), " "),
+			level:     strings.ToLower(field(record, levelCol)),
+			severity:  windowsSeverity(field(record, levelCol)),
+			source:    firstNonEmpty(field(record, providerCol), source),
+		})
+	}
+	return logs, nil
+}
+
+// windowsEvent is the subset of the event schema the analyzer uses.
+type windowsEvent struct {
+	System struct {
+		Provider struct {
+			Name string `xml:"Name,attr"`
+		} `xml:"Provider"`
+		Level       string `xml:"Level"`
+		TimeCreated struct {
+			SystemTime string `xml:"SystemTime,attr"`
+		} `xml:"TimeCreated"`
+	} `xml:"System"`
+	RenderingInfo struct {
+		Level   string `xml:"Level"`
+		Message string `xml:"Message"`
+	} `xml:"RenderingInfo"`
+	EventData struct {
+		Data []string `xml:"Data"`
+	} `xml:"EventData"`
+}
+
+// isWindowsXML reports whether text opens like an event export, so that
+// other sources starting with "<", such as syslog priorities, aren't taken
+// for XML.
+func isWindowsXML(text string) bool {
+	text = strings.TrimSpace(text)
+	return strings.HasPrefix(text, "<?xml") || strings.HasPrefix(text, "<Event")
+}
+
+// parseWindowsXML reads a wevtutil or Get-WinEvent XML export, whose root
+// is either <Events> or a bare sequence of <Event> elements.
+func parseWindowsXML(text, source string) ([]Log, error) {
+	dec := xml.NewDecoder(strings.NewReader(text))
+	var logs []Log
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Event" {
+			continue
+		}
+		var event windowsEvent
+		if err := dec.DecodeElement(&event, &start); err != nil {
+			return nil, err
+		}
+
+		level := firstNonEmpty(event.RenderingInfo.Level, event.System.Level)
+		message := firstNonEmpty(event.RenderingInfo.Message, strings.Join(event.EventData.Data, " "))
+		logs = append(logs, Log{
+			timestamp: windowsTimestamp(event.System.TimeCreated.SystemTime),
+			message:   strings.Join(strings.Fields(message), " "),
+			level:     strings.ToLower(level),
+			severity:  windowsSeverity(level),
+			source:    firstNonEmpty(event.System.Provider.Name, source),
+		})
+	}
+	if logs == nil && !strings.Contains(text, "<Event") {
+		return nil, fmt.Errorf("%s: no Windows events found", source)
+	}
+	return logs, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}